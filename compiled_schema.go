@@ -35,6 +35,7 @@ type CompiledSchema []flatValidator
 // Check executes the the checks within the CompiledSchema
 func (cs CompiledSchema) Check(actual interface{}) *llresult.Results {
 	res := llresult.NewResults()
+	doc := isdef.NewDocument(actual)
 	for _, pv := range cs {
 		actualVal, actualKeyExists := pv.path.GetFrom(reflect.ValueOf(actual))
 		var actualInter interface{}
@@ -45,10 +46,17 @@ func (cs CompiledSchema) Check(actual interface{}) *llresult.Results {
 
 		if !pv.isDef.Optional || pv.isDef.Optional && actualKeyExists {
 			var checkRes *llresult.Results
-			checkRes = pv.isDef.Check(pv.path, actualInter, actualKeyExists)
+			checkRes = pv.isDef.CheckInDocument(doc, pv.path, actualInter, actualKeyExists)
 			res.Merge(checkRes)
 		}
 	}
 
 	return res
 }
+
+// Each calls fn with the path and IsDef of every check in the CompiledSchema, in the order they are run.
+func (cs CompiledSchema) Each(fn func(path llpath.Path, def isdef.IsDef)) {
+	for _, pv := range cs {
+		fn(pv.path, pv.isDef)
+	}
+}
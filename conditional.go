@@ -0,0 +1,114 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lookslike
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/elastic/go-lookslike/isdef"
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+	"github.com/elastic/go-lookslike/validator"
+)
+
+// If runs the then validator when condition passes for the actual value, and the otherwise validator when it
+// does not. The results of condition are only used to choose a branch and are not included in the output.
+// Either branch may be nil, in which case nothing is validated for that branch.
+func If(condition, then, otherwise validator.Validator) validator.Validator {
+	thenBranch, otherwiseBranch := Compose(nonNil(then)...), Compose(nonNil(otherwise)...)
+	return func(actual interface{}) *llresult.Results {
+		if condition(actual).Valid {
+			return thenBranch(actual)
+		}
+		return otherwiseBranch(actual)
+	}
+}
+
+// nonNil returns the given validators, leaving out nil ones.
+func nonNil(validators ...validator.Validator) []validator.Validator {
+	var out []validator.Validator
+	for _, v := range validators {
+		if v != nil {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+type switchDefault struct{}
+
+// SwitchDefault can be used as a key in the cases given to Switch to match any value without its own case.
+var SwitchDefault = switchDefault{}
+
+// Switch chooses a validator based on the value at the given path, in the way If chooses between two.
+// The value is compared with the keys of cases for equality. The chosen validator is composed with a check
+// that the value has a case, so a value without a case, or a missing key, is reported at the given path unless
+// a SwitchDefault case is present. Switch panics if path is not a valid path.
+//
+//	Switch("monitor.type", map[interface{}]validator.Validator{
+//		"http": httpValidator,
+//		"icmp": icmpValidator,
+//	})
+func Switch(path string, cases map[interface{}]validator.Validator) validator.Validator {
+	p := llpath.MustParsePath(path)
+	defaultCase, hasDefault := cases[SwitchDefault]
+
+	keys := make([]interface{}, 0, len(cases))
+	for k := range cases {
+		if k != SwitchDefault {
+			keys = append(keys, k)
+		}
+	}
+
+	choose := func(v interface{}) validator.Validator {
+		if v == nil || reflect.TypeOf(v).Comparable() {
+			if chosen, ok := cases[v]; ok {
+				return chosen
+			}
+		}
+		return defaultCase
+	}
+
+	discriminator := isdef.Is("has a switch case", func(path llpath.Path, v interface{}) *llresult.Results {
+		if choose(v) == nil {
+			return llresult.SimpleResult(path, false, fmt.Sprintf("Value %#v matched none of the cases %#v", v, keys))
+		}
+		return llresult.ValidResult(path)
+	})
+	if hasDefault {
+		discriminator = isdef.Optional(discriminator)
+	}
+	checkDiscriminator := CompiledSchema{flatValidator{p, discriminator}}.Check
+
+	return func(actual interface{}) *llresult.Results {
+		chosen := defaultCase
+		if val, exists := p.GetFrom(reflect.ValueOf(actual)); exists {
+			var value interface{}
+			if val.IsValid() {
+				value = val.Interface()
+			}
+			chosen = choose(value)
+		}
+
+		if chosen == nil {
+			return checkDiscriminator(actual)
+		}
+		return Compose(checkDiscriminator, chosen)(actual)
+	}
+}
@@ -38,10 +38,8 @@ func Compose(validators ...validator.Validator) validator.Validator {
 
 		combined := llresult.NewResults()
 		for _, r := range res {
-			r.EachResult(func(path llpath.Path, vr llresult.ValueResult) bool {
-				combined.Record(path, vr)
-				return true
-			})
+			// Merge rather than recording each result, so that paths marked as sensitive stay marked
+			combined.Merge(r)
 		}
 		return combined
 	}
@@ -96,7 +94,22 @@ func Strict(laxValidator validator.Validator) validator.Validator {
 	}
 }
 
-func compile(in interface{}) (validator.Validator, error) {
+// Compiler compiles definitions into validators. The zero value is ready to use and behaves like
+// MustCompile and CompileSchema.
+type Compiler struct {
+	// Equal holds the equality handlers used for plain values in definitions, DefaultEqualRegistry if nil.
+	Equal *isdef.EqualRegistry
+}
+
+func (c Compiler) equal() *isdef.EqualRegistry {
+	if c.Equal != nil {
+		return c.Equal
+	}
+	return isdef.DefaultEqualRegistry
+}
+
+// Compile compiles the given definition into a validator.Validator.
+func (c Compiler) Compile(in interface{}) (validator.Validator, error) {
 	switch in.(type) {
 	case isdef.IsDef:
 		return compileIsDef(in.(isdef.IsDef))
@@ -107,17 +120,26 @@ func compile(in interface{}) (validator.Validator, error) {
 		inVal := reflect.ValueOf(in)
 		switch inVal.Kind() {
 		case reflect.Map:
-			return compileMap(inVal)
+			return c.compileMap(inVal)
 		case reflect.Slice, reflect.Array:
-			return compileSlice(inVal)
+			return c.compileSlice(inVal)
 		default:
-			return compileIsDef(isdef.IsEqual(in))
+			return compileIsDef(c.equal().IsEqual(in))
 		}
 	}
 }
 
-func compileMap(inVal reflect.Value) (validator validator.Validator, err error) {
-	wo, compiled := setupWalkObserver()
+// MustCompile is the panic-ing equivalent of Compile.
+func (c Compiler) MustCompile(in interface{}) validator.Validator {
+	compiled, err := c.Compile(in)
+	if err != nil {
+		panic(err)
+	}
+	return compiled
+}
+
+func (c Compiler) compileMap(inVal reflect.Value) (validator validator.Validator, err error) {
+	wo, compiled := c.setupWalkObserver()
 	err = walkMap(inVal, true, wo)
 
 	return func(actual interface{}) *llresult.Results {
@@ -125,8 +147,8 @@ func compileMap(inVal reflect.Value) (validator validator.Validator, err error)
 	}, err
 }
 
-func compileSlice(inVal reflect.Value) (validator validator.Validator, err error) {
-	wo, compiled := setupWalkObserver()
+func (c Compiler) compileSlice(inVal reflect.Value) (validator validator.Validator, err error) {
+	wo, compiled := c.setupWalkObserver()
 	err = walkSlice(inVal, true, wo)
 
 	// Slices are always strict in validation because
@@ -138,11 +160,12 @@ func compileSlice(inVal reflect.Value) (validator validator.Validator, err error
 
 func compileIsDef(def isdef.IsDef) (validator validator.Validator, err error) {
 	return func(actual interface{}) *llresult.Results {
-		return def.Check(llpath.Path{}, actual, true)
+		return def.CheckInDocument(isdef.NewDocument(actual), llpath.Path{}, actual, true)
 	}, nil
 }
 
-func setupWalkObserver() (walkObserver, *CompiledSchema) {
+func (c Compiler) setupWalkObserver() (walkObserver, *CompiledSchema) {
+	equal := c.equal()
 	compiled := make(CompiledSchema, 0)
 	return func(current walkObserverInfo) error {
 		kind := current.value.Kind()
@@ -152,9 +175,15 @@ func setupWalkObserver() (walkObserver, *CompiledSchema) {
 		// We do comparisons on all leaf nodes. If the leaf is an empty collection
 		// we do a comparison to let us test empty structures.
 		if !isCollection || isEmptyCollection {
+			// nil values in the schema are unwrapped to the zero reflect.Value
+			if !current.value.IsValid() {
+				compiled = append(compiled, flatValidator{current.path, isdef.IsNil})
+				return nil
+			}
+
 			isDef, isIsDef := current.value.Interface().(isdef.IsDef)
 			if !isIsDef {
-				isDef = isdef.IsEqual(current.value.Interface())
+				isDef = equal.IsEqual(current.value.Interface())
 			}
 
 			compiled = append(compiled, flatValidator{current.path, isDef})
@@ -163,11 +192,40 @@ func setupWalkObserver() (walkObserver, *CompiledSchema) {
 	}, &compiled
 }
 
+// CompileSchema compiles the given definition like MustCompile, but returns the CompiledSchema rather than a
+// validator.Validator, so the checks it contains can be inspected. A definition that is not a map or slice
+// compiles to a single check at the root path. Unlike MustCompile, slices are not checked strictly.
+func CompileSchema(in interface{}) (CompiledSchema, error) {
+	return Compiler{}.CompileSchema(in)
+}
+
+// CompileSchema is like the package level CompileSchema, but uses the Compiler's settings.
+func (c Compiler) CompileSchema(in interface{}) (CompiledSchema, error) {
+	var def isdef.IsDef
+	switch v := in.(type) {
+	case isdef.IsDef:
+		def = v
+	case nil:
+		def = isdef.IsNil
+	default:
+		inVal := reflect.ValueOf(in)
+		switch inVal.Kind() {
+		case reflect.Map:
+			wo, compiled := c.setupWalkObserver()
+			err := walkMap(inVal, true, wo)
+			return *compiled, err
+		case reflect.Slice, reflect.Array:
+			wo, compiled := c.setupWalkObserver()
+			err := walkSlice(inVal, true, wo)
+			return *compiled, err
+		default:
+			def = c.equal().IsEqual(in)
+		}
+	}
+	return CompiledSchema{flatValidator{llpath.Path{}, def}}, nil
+}
+
 // MustCompile compiles the given validation, panic-ing if that map is invalid.
 func MustCompile(in interface{}) validator.Validator {
-	compiled, err := compile(in)
-	if err != nil {
-		panic(err)
-	}
-	return compiled
+	return Compiler{}.MustCompile(in)
 }
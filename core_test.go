@@ -19,6 +19,7 @@ package lookslike
 
 import (
 	"regexp"
+	"sort"
 	"testing"
 	"time"
 
@@ -469,7 +470,7 @@ func TestMatchArrayAsValue(t *testing.T) {
 
 func TestInvalidPathIsdef(t *testing.T) {
 	badPath := "foo...bar"
-	_, err := compile(map[string]interface{}{
+	_, err := Compiler{}.Compile(map[string]interface{}{
 		badPath: "invalid",
 	})
 
@@ -511,6 +512,25 @@ func TestStrictNil(t *testing.T) {
 	require.False(t, v(1).Valid)
 }
 
+func TestStrictSliceMatchers(t *testing.T) {
+	m := map[string]interface{}{"tags": []interface{}{"b", "a", "c"}}
+
+	for name, def := range map[string]isdef.IsDef{
+		"non-empty":  isdef.IsNonEmptySlice,
+		"length":     isdef.IsSliceOfLength(3),
+		"containing": isdef.IsSliceContaining(MustCompile("a")),
+		"all":        isdef.IsSliceContainingAll(MustCompile("a")),
+	} {
+		res := Strict(MustCompile(map[string]interface{}{"tags": def}))(m)
+		assert.True(t, res.Valid, name)
+	}
+
+	res := Strict(MustCompile(map[string]interface{}{"tags": isdef.IsSliceSorted}))(
+		map[string]interface{}{"tags": []int{1, 2, 3}},
+	)
+	assert.True(t, res.Valid)
+}
+
 func TestPrimitives(t *testing.T) {
 	type testyStructy struct {
 		member1 bool
@@ -600,3 +620,275 @@ func TestUnderlyingMap(t *testing.T) {
 	assert.False(t, res.Fields["baz"][0].Valid)
 	assert.Len(t, res.Errors(), 1)
 }
+
+func TestDecodedStringResultsNestUnderPath(t *testing.T) {
+	m := map[string]interface{}{
+		"message": `{"user": {"name": "alice"}, "status": 200}`,
+	}
+
+	v := MustCompile(map[string]interface{}{
+		"message": isdef.IsJSONString(MustCompile(map[string]interface{}{
+			"user.name": "alice",
+			"status":    isdef.IsIntGt(300),
+		})),
+	})
+
+	res := v(m)
+	assert.False(t, res.Valid)
+	assert.True(t, res.Fields["message.user.name"][0].Valid)
+	assert.False(t, res.Fields["message.status"][0].Valid)
+}
+
+func TestLogicalCombinatorsKeyPresence(t *testing.T) {
+	m := map[string]interface{}{
+		"present": "foo",
+	}
+
+	v := MustCompile(map[string]interface{}{
+		"present":    isdef.All(isdef.IsString, isdef.Not(isdef.IsEqual("bar"))),
+		"missing":    isdef.Optional(isdef.Not(isdef.IsEqual("bar"))),
+		"required":   isdef.Not(isdef.IsEqual("bar")),
+		"maybe":      isdef.ExactlyOne(isdef.KeyMissing, isdef.IsString),
+		"notMissing": isdef.Not(isdef.KeyMissing),
+	})
+
+	res := v(m)
+	assert.False(t, res.Valid)
+	assert.True(t, res.Fields["present"][0].Valid)
+	assert.NotContains(t, res.Fields, "missing")
+	assert.Equal(t, llresult.KeyMissingVR, res.Fields["required"][0])
+	assert.NotContains(t, res.Fields, "maybe")
+	assert.Equal(t, llresult.KeyMissingVR, res.Fields["notMissing"][0])
+}
+
+func TestIsSchemaComposition(t *testing.T) {
+	userSchema := MustCompile(map[string]interface{}{
+		"name": isdef.IsNonEmptyString,
+		"id":   isdef.IsIntGt(0),
+	})
+
+	v := Strict(MustCompile(map[string]interface{}{
+		"user":     isdef.IsAny(isdef.IsString, isdef.IsSchema(userSchema)),
+		"approver": isdef.Optional(isdef.IsSchema(userSchema)),
+	}))
+
+	assertResults(t, v(map[string]interface{}{"user": "alice"}))
+
+	res := v(map[string]interface{}{
+		"user":     map[string]interface{}{"name": "alice", "id": 1},
+		"approver": map[string]interface{}{"name": "bob", "id": 2},
+	})
+	assertResults(t, res)
+	assert.True(t, res.Fields["user.name"][0].Valid)
+	assert.True(t, res.Fields["approver.id"][0].Valid)
+
+	badRes := v(map[string]interface{}{
+		"user": map[string]interface{}{"name": "alice", "id": 0},
+	})
+	assert.False(t, badRes.Valid)
+	assert.False(t, badRes.Fields["user.id"][0].Valid)
+	assert.True(t, badRes.Fields["user.name"][0].Valid)
+
+	strictRes := v(map[string]interface{}{
+		"user": map[string]interface{}{"name": "alice", "id": 1, "extra": true},
+	})
+	assert.False(t, strictRes.Valid)
+	assert.Equal(t, []llresult.ValueResult{llresult.StrictFailureVR}, strictRes.Fields["user.extra"])
+}
+
+func TestMapMatchersWithStrict(t *testing.T) {
+	m := map[string]interface{}{
+		"labels": map[string]interface{}{"env": "prod", "team": "obs"},
+	}
+
+	v := Strict(MustCompile(map[string]interface{}{
+		"labels": isdef.All(
+			isdef.IsMapKeysMatching(regexp.MustCompile(`^[a-z]+$`)),
+			isdef.IsMapOf(MustCompile(isdef.IsNonEmptyString)),
+		),
+	}))
+	assertResults(t, v(m))
+
+	// Keys other than the required ones are accepted by IsMapWithKeys, so Strict does not flag them
+	partial := Strict(MustCompile(map[string]interface{}{
+		"labels": isdef.IsMapWithKeys("env"),
+	}))
+	res := partial(m)
+	assertResults(t, res)
+	assert.True(t, res.Fields["labels.env"][0].Valid)
+	assert.True(t, res.Fields["labels.team"][0].Valid)
+
+	assertResults(t, Strict(MustCompile(map[string]interface{}{
+		"labels": isdef.IsMapLengthBetween(1, 2),
+	}))(m))
+}
+
+func TestCrossFieldChecks(t *testing.T) {
+	start := time.Now()
+	v := MustCompile(map[string]interface{}{
+		"event.end": isdef.IsGteField(".start"),
+		"summary.total": isdef.IsInDocument("is sum of up and down", func(doc isdef.Document, path llpath.Path, v interface{}) *llresult.Results {
+			up, _ := doc.Get(llpath.MustParsePath("summary.up"))
+			down, _ := doc.Get(llpath.MustParsePath("summary.down"))
+			if up.(int)+down.(int) != v.(int) {
+				return llresult.SimpleResult(path, false, "%v is not %v + %v", v, up, down)
+			}
+			return llresult.ValidResult(path)
+		}),
+		"monitors": isdef.IsSliceOf(MustCompile(map[string]interface{}{
+			// Nested validators resolve paths within the element being validated
+			"id": isdef.IsEqualToField("name"),
+		})),
+	})
+
+	good := map[string]interface{}{
+		"event":    map[string]interface{}{"start": start, "end": start.Add(time.Second)},
+		"summary":  map[string]interface{}{"up": 1, "down": 2, "total": 3},
+		"monitors": []interface{}{map[string]interface{}{"id": "a", "name": "a"}},
+	}
+	assertResults(t, v(good))
+
+	bad := map[string]interface{}{
+		"event":    map[string]interface{}{"start": start, "end": start.Add(-time.Second)},
+		"summary":  map[string]interface{}{"up": 1, "down": 2, "total": 4},
+		"monitors": []interface{}{map[string]interface{}{"id": "a", "name": "b"}},
+	}
+	res := v(bad)
+	assert.False(t, res.Fields["event.end"][0].Valid)
+	assert.False(t, res.Fields["summary.total"][0].Valid)
+	assert.False(t, res.Fields["monitors.[0].id"][0].Valid)
+}
+
+func TestIf(t *testing.T) {
+	isHTTP := MustCompile(map[string]interface{}{"monitor.type": "http"})
+	v := If(
+		isHTTP,
+		MustCompile(map[string]interface{}{"http.response.status_code": isdef.IsIntGt(0)}),
+		MustCompile(map[string]interface{}{"http": isdef.KeyMissing}),
+	)
+
+	assertResults(t, v(map[string]interface{}{
+		"monitor": map[string]interface{}{"type": "http"},
+		"http":    map[string]interface{}{"response": map[string]interface{}{"status_code": 200}},
+	}))
+	assertResults(t, v(map[string]interface{}{
+		"monitor": map[string]interface{}{"type": "icmp"},
+	}))
+
+	res := v(map[string]interface{}{
+		"monitor": map[string]interface{}{"type": "icmp"},
+		"http":    map[string]interface{}{},
+	})
+	assert.False(t, res.Valid)
+	// The condition's own results are not reported
+	assert.NotContains(t, res.Fields, "monitor.type")
+
+	noElse := If(isHTTP, MustCompile(map[string]interface{}{"http": isdef.KeyPresent}), nil)
+	assertResults(t, noElse(map[string]interface{}{"monitor": map[string]interface{}{"type": "tcp"}}))
+
+	// Branch results are combined with Compose, which keeps sensitive paths marked
+	sensitive := If(isHTTP, MustCompile(map[string]interface{}{"token": isdef.Sensitive(isdef.IsString)}), nil)
+	res = sensitive(map[string]interface{}{"monitor": map[string]interface{}{"type": "http"}, "token": "t"})
+	assertResults(t, res)
+	assert.True(t, res.IsSensitive(llpath.MustParsePath("token")))
+}
+
+func TestSwitch(t *testing.T) {
+	v := Strict(Switch("monitor.type", map[interface{}]validator.Validator{
+		"http": MustCompile(map[string]interface{}{"http.url": isdef.IsURL("http", "https")}),
+		"icmp": MustCompile(map[string]interface{}{"icmp.rtt": isdef.IsDuration}),
+	}))
+
+	assertResults(t, v(map[string]interface{}{
+		"monitor": map[string]interface{}{"type": "http"},
+		"http":    map[string]interface{}{"url": "https://example.net"},
+	}))
+	assertResults(t, v(map[string]interface{}{
+		"monitor": map[string]interface{}{"type": "icmp"},
+		"icmp":    map[string]interface{}{"rtt": time.Millisecond},
+	}))
+
+	res := v(map[string]interface{}{
+		"monitor": map[string]interface{}{"type": "icmp"},
+		"icmp":    map[string]interface{}{"rtt": time.Millisecond},
+		"http":    map[string]interface{}{"url": "https://example.net"},
+	})
+	assert.Equal(t, []llresult.ValueResult{llresult.StrictFailureVR}, res.Fields["http.url"])
+
+	res = v(map[string]interface{}{"monitor": map[string]interface{}{"type": "tcp"}})
+	require.False(t, res.Valid)
+	assert.Contains(t, res.Fields["monitor.type"][0].Message, "matched none of the cases")
+
+	res = v(map[string]interface{}{})
+	assert.Equal(t, llresult.KeyMissingVR, res.Fields["monitor.type"][0])
+
+	// Unhashable values never match a case
+	res = v(map[string]interface{}{"monitor": map[string]interface{}{"type": []string{"http"}}})
+	assert.False(t, res.Valid)
+}
+
+func TestSwitchDefault(t *testing.T) {
+	v := Switch("kind", map[interface{}]validator.Validator{
+		1:             MustCompile(map[string]interface{}{"one": true}),
+		SwitchDefault: MustCompile(map[string]interface{}{"other": true}),
+	})
+
+	assertResults(t, v(map[string]interface{}{"kind": 1, "one": true}))
+	assertResults(t, v(map[string]interface{}{"kind": 2, "other": true}))
+	assertResults(t, v(map[string]interface{}{"other": true}))
+	assert.False(t, v(map[string]interface{}{"kind": 2, "one": true}).Valid)
+}
+
+func TestNilInSchema(t *testing.T) {
+	v := MustCompile(map[string]interface{}{
+		"a": nil,
+		"b": map[string]interface{}{"c": nil},
+	})
+
+	assertResults(t, v(map[string]interface{}{"a": nil, "b": map[string]interface{}{"c": nil}}))
+
+	res := v(map[string]interface{}{"a": 1, "b": map[string]interface{}{}})
+	assert.False(t, res.Fields["a"][0].Valid)
+	assert.Equal(t, llresult.KeyMissingVR, res.Fields["b.c"][0])
+}
+
+func TestCompileSchema(t *testing.T) {
+	cs, err := CompileSchema(map[string]interface{}{
+		"a": map[string]interface{}{"b": isdef.IsString},
+		"c": []interface{}{1},
+	})
+	require.NoError(t, err)
+
+	var paths []string
+	cs.Each(func(path llpath.Path, def isdef.IsDef) {
+		paths = append(paths, path.String())
+	})
+	sort.Strings(paths)
+	assert.Equal(t, []string{"a.b", "c.[0]"}, paths)
+	assertResults(t, cs.Check(map[string]interface{}{"a": map[string]interface{}{"b": "x"}, "c": []int{1}}))
+
+	cs, err = CompileSchema(isdef.IsString)
+	require.NoError(t, err)
+	require.Len(t, cs, 1)
+	assertResults(t, cs.Check("x"))
+	assert.False(t, cs.Check(1).Valid)
+}
+
+func TestCompilerEqual(t *testing.T) {
+	equal := isdef.NewEqualRegistry()
+	require.NoError(t, equal.Set(func(to float64) isdef.IsDef {
+		return isdef.All(isdef.IsGt(to-0.01), isdef.IsLt(to+0.01))
+	}))
+
+	schema := map[string]interface{}{"ratio": 0.5, "name": "x"}
+	v := Compiler{Equal: equal}.MustCompile(schema)
+	assert.True(t, v(map[string]interface{}{"ratio": 0.501, "name": "x"}).Valid)
+	assert.False(t, v(map[string]interface{}{"ratio": 0.6, "name": "x"}).Valid)
+
+	// The default registry is unchanged
+	assert.False(t, MustCompile(schema)(map[string]interface{}{"ratio": 0.501, "name": "x"}).Valid)
+
+	cs, err := Compiler{Equal: equal}.CompileSchema(0.5)
+	require.NoError(t, err)
+	assert.True(t, cs.Check(0.499).Valid)
+}
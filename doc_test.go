@@ -24,6 +24,7 @@ import (
 	"github.com/elastic/go-lookslike/isdef"
 	"github.com/elastic/go-lookslike/llpath"
 	"github.com/elastic/go-lookslike/llresult"
+	"github.com/elastic/go-lookslike/validator"
 )
 
 func Example() {
@@ -205,3 +206,32 @@ func ExampleSlice() {
 	// Output:
 	// Result is true
 }
+
+func ExampleSwitch() {
+	// Switch lets a single validator describe a family of documents whose shape depends on one field.
+	httpValidator := MustCompile(map[string]interface{}{"http.response.status_code": isdef.IsIntGt(0)})
+	icmpValidator := MustCompile(map[string]interface{}{"http": isdef.KeyMissing})
+
+	v := Compose(
+		MustCompile(map[string]interface{}{"monitor.id": isdef.IsNonEmptyString}),
+		Switch("monitor.type", map[interface{}]validator.Validator{
+			"http": httpValidator,
+			"icmp": icmpValidator,
+		}),
+	)
+
+	events := []map[string]interface{}{
+		{"monitor": map[string]interface{}{"id": "a", "type": "http"}, "http": map[string]interface{}{"response": map[string]interface{}{"status_code": 200}}},
+		{"monitor": map[string]interface{}{"id": "b", "type": "icmp"}},
+		{"monitor": map[string]interface{}{"id": "c", "type": "icmp"}, "http": map[string]interface{}{}},
+	}
+
+	for _, e := range events {
+		fmt.Printf("%v: %t\n", e["monitor"].(map[string]interface{})["id"], v(e).Valid)
+	}
+
+	// Output:
+	// a: true
+	// b: true
+	// c: false
+}
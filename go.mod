@@ -3,12 +3,11 @@ module github.com/elastic/go-lookslike
 go 1.18
 
 require (
-	github.com/kortschak/utter v1.5.0
 	github.com/stretchr/testify v1.8.4
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
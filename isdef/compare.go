@@ -0,0 +1,42 @@
+package isdef
+
+import (
+	"fmt"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+)
+
+func comparison(matcher string, name string, to interface{}, valid func(cmp int) bool) IsDef {
+	return Is(name, func(path llpath.Path, v interface{}) *llresult.Results {
+		cmp, err := compareValues(v, to)
+		if err != nil {
+			return llresult.SimpleResult(path, false, fmt.Sprintf("Could not compare '%v' with '%v': %s", v, to, err))
+		}
+		if !valid(cmp) {
+			return llresult.SimpleResult(path, false, fmt.Sprintf("%v is not %s %v", v, name, to))
+		}
+		return llresult.ValidResult(path)
+	}).withSpec(matcher, to)
+}
+
+// IsGt checks that the value is greater than the given one. Numbers of any kind can be compared with
+// each other, as can two strings or two time.Times.
+func IsGt(than interface{}) IsDef {
+	return comparison("isGt", "greater than", than, func(cmp int) bool { return cmp > 0 })
+}
+
+// IsGte checks that the value is greater than or equal to the given one.
+func IsGte(than interface{}) IsDef {
+	return comparison("isGte", "greater than or equal to", than, func(cmp int) bool { return cmp >= 0 })
+}
+
+// IsLt checks that the value is less than the given one.
+func IsLt(than interface{}) IsDef {
+	return comparison("isLt", "less than", than, func(cmp int) bool { return cmp < 0 })
+}
+
+// IsLte checks that the value is less than or equal to the given one.
+func IsLte(than interface{}) IsDef {
+	return comparison("isLte", "less than or equal to", than, func(cmp int) bool { return cmp <= 0 })
+}
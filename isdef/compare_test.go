@@ -0,0 +1,24 @@
+package isdef
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComparisons(t *testing.T) {
+	assertIsDefValid(t, IsGt(0), 1)
+	assertIsDefValid(t, IsGt(0), 0.5)
+	assertIsDefValid(t, IsGt(int64(0)), uint8(1))
+	assertIsDefInvalid(t, IsGt(0), 0)
+	assertIsDefInvalid(t, IsGt(0), "1")
+
+	assertIsDefValid(t, IsGte(1.5), 1.5)
+	assertIsDefInvalid(t, IsGte(1.5), 1)
+
+	assertIsDefValid(t, IsLt("b"), "a")
+	assertIsDefInvalid(t, IsLt("b"), "b")
+
+	now := time.Now()
+	assertIsDefValid(t, IsLte(now), now)
+	assertIsDefInvalid(t, IsLte(now), now.Add(time.Second))
+}
@@ -8,33 +8,20 @@ import (
 	"github.com/elastic/go-lookslike/llresult"
 )
 
-// IsEqual tests that the given object is equal to the actual object.
+// IsEqual tests that the given object is equal to the actual object. Equality is checked, in order of preference, by
+//   - a handler in DefaultEqualRegistry for the object's type,
+//   - a handler in DefaultEqualRegistry for an interface the object's type implements,
+//   - an Equal(T) bool, Compare(T) int or Cmp(T) int method on the object, where T is its type or a pointer to it,
+//   - reflect.DeepEqual.
 func IsEqual(to interface{}) IsDef {
-	toV := reflect.ValueOf(to)
-	isDefFactory, ok := equalChecks[toV.Type()]
-
-	// If there are no handlers declared explicitly for this type we perform a deep equality check
-	if !ok {
-		return IsDeepEqual(to)
-	}
-
-	// We know this is an isdef due to the Register check previously
-	checker := isDefFactory.Call([]reflect.Value{toV})[0].Interface().(IsDef).Checker
-
-	return Is("equals", func(path llpath.Path, v interface{}) *llresult.Results {
-		return checker(path, v)
-	})
+	return DefaultEqualRegistry.IsEqual(to)
 }
 
 // KeyPresent checks that the given key is in the map, even if it has a nil value.
-var KeyPresent = IsDef{Name: "check key present"}
+var KeyPresent = IsDef{Name: "check key present", Spec: &Spec{Matcher: "keyPresent"}}
 
 // KeyMissing checks that the given key is not present defined.
-var KeyMissing = IsDef{Name: "check key not present", CheckKeyMissing: true}
-
-func init() {
-	MustRegisterEqual(IsEqualToTime)
-}
+var KeyMissing = IsDef{Name: "check key not present", CheckKeyMissing: true, Spec: &Spec{Matcher: "keyMissing"}}
 
 // InvalidEqualFnError is the error type returned by RegisterEqual when
 // there is an issue with the given function.
@@ -46,54 +33,24 @@ func (e InvalidEqualFnError) Error() string {
 
 // MustRegisterEqual is the panic-ing equivalent of RegisterEqual.
 func MustRegisterEqual(fn interface{}) {
-	if err := RegisterEqual(fn); err != nil {
-		panic(fmt.Sprintf("Could not register fn as equal! %v", err))
-	}
+	DefaultEqualRegistry.MustRegister(fn)
 }
 
-var equalChecks = map[reflect.Type]reflect.Value{}
-
 // RegisterEqual takes a function of the form fn(v someType) IsDef
-// and registers it to check equality for that type.
+// and registers it in DefaultEqualRegistry to check equality for that type.
 func RegisterEqual(fn interface{}) error {
-	fnV := reflect.ValueOf(fn)
-	fnT := fnV.Type()
-
-	if fnT.Kind() != reflect.Func {
-		return InvalidEqualFnError{"Provided value is not a function"}
-	}
-	if fnT.NumIn() != 1 {
-		return InvalidEqualFnError{"Equal FN should take one argument"}
-	}
-	if fnT.NumOut() != 1 {
-		return InvalidEqualFnError{"Equal FN should return one value"}
-	}
-	if fnT.Out(0) != reflect.TypeOf(IsDef{}) {
-		return InvalidEqualFnError{"Equal FN should return an IsDef"}
-	}
-
-	inT := fnT.In(0)
-	if _, ok := equalChecks[inT]; ok {
-		return InvalidEqualFnError{fmt.Sprintf("Duplicate Equal FN for type %v encountered!", inT)}
-	}
-
-	equalChecks[inT] = fnV
-
-	return nil
+	return DefaultEqualRegistry.Register(fn)
 }
 
-// IsDeepEqual checks equality using reflect.DeepEqual.
+// IsDeepEqual checks equality using reflect.DeepEqual. On failure the differences are reported individually,
+// at their own paths below the checked one when comparing maps and slices.
 func IsDeepEqual(to interface{}) IsDef {
 	return Is("equals", func(path llpath.Path, v interface{}) *llresult.Results {
 		if reflect.DeepEqual(v, to) {
 			return llresult.ValidResult(path)
 		}
-		return llresult.SimpleResult(
-			path,
-			false,
-			fmt.Sprintf("objects not equal: actual(%T(%v)) != expected(%T(%v))", v, v, to, to),
-		)
-	})
+		return deepEqualResults(path, v, to)
+	}).withSpec("isDeepEqual", to)
 }
 
 // IsNil tests that a value is nil.
@@ -106,4 +63,4 @@ var IsNil = Is("is nil", func(path llpath.Path, v interface{}) *llresult.Results
 		false,
 		fmt.Sprintf("Value %#v is not nil", v),
 	)
-})
+}).withSpec("isNil")
@@ -7,6 +7,7 @@ import (
 	"github.com/elastic/go-lookslike/llpath"
 	"github.com/elastic/go-lookslike/llresult"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func assertIsDefValid(t *testing.T, id IsDef, value interface{}) *llresult.Results {
@@ -45,6 +46,39 @@ func TestIsAny(t *testing.T) {
 	assertIsDefInvalid(t, id, "basta")
 }
 
+func TestIsAnyDiagnostics(t *testing.T) {
+	id := IsAny(IsStringContaining("foo"), IsIntGt(3))
+
+	res := assertIsDefInvalid(t, id, "bar")
+	errs := res.Errors()
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0].Error(), "Value was none of")
+	assert.Contains(t, errs[1].Error(), "branch 0 'is string containing' failed: String 'bar' did not contain substring 'foo'")
+	assert.Contains(t, errs[2].Error(), "branch 1 'greater than' failed: bar is a string, but was expecting an int!")
+}
+
+func TestIsAnyClosestNestedBranch(t *testing.T) {
+	// Each branch validates a different key of a nested map
+	isPoint := IsDecodedString("point", JSONDecoder, func(actual interface{}) *llresult.Results {
+		res := keyValidator("x", IsEqual(1))(actual)
+		res.Merge(keyValidator("y", IsEqual(2))(actual))
+		return res
+	})
+	isName := IsDecodedString("name", JSONDecoder, keyValidator("name", IsString))
+
+	id := IsAny(isName, isPoint)
+	assertIsDefValid(t, id, `{"x": 1, "y": 2}`)
+
+	res := assertIsDefInvalid(t, id, `{"x": 1, "y": 3}`)
+	assert.Contains(t, res.Fields["p"][0].Message, "closest match was 'point'")
+	assert.Contains(t, res.Fields["p"][2].Message, "@y: objects not equal")
+	// The closest branch's nested results are reported at their own paths
+	require.Contains(t, res.Fields, "p.y")
+	assert.False(t, res.Fields["p.y"][0].Valid)
+	assert.True(t, res.Fields["p.x"][0].Valid)
+	assert.NotContains(t, res.Fields, "p.name")
+}
+
 func TestIsEqual(t *testing.T) {
 	id := IsEqual("foo")
 
@@ -66,3 +100,102 @@ func TestIsNil(t *testing.T) {
 	assertIsDefValid(t, IsNil, nil)
 	assertIsDefInvalid(t, IsNil, "foo")
 }
+
+func TestAll(t *testing.T) {
+	id := All(IsString, IsStringContaining("foo"), IsStringWithSuffix("bar"))
+
+	assertIsDefValid(t, id, "foobar")
+	res := assertIsDefInvalid(t, id, "foobaz")
+	assert.Len(t, res.Errors(), 1)
+	res = assertIsDefInvalid(t, id, 123)
+	assert.Len(t, res.Errors(), 3)
+
+	assertIsDefValid(t, All(), "anything")
+}
+
+func TestAllKeySemantics(t *testing.T) {
+	p := llpath.MustParsePath("p")
+
+	required := All(Optional(IsString), IsStringContaining("a"))
+	assert.False(t, required.Optional)
+	assert.False(t, required.Check(p, nil, false).Valid)
+
+	optional := All(Optional(IsString), Optional(IsStringContaining("a")))
+	assert.True(t, optional.Optional)
+
+	missing := All(KeyMissing, KeyMissing)
+	assert.True(t, missing.Check(p, nil, false).Valid)
+	assert.False(t, missing.Check(p, "x", true).Valid)
+
+	// Both accept a missing key, but a present key fails KeyMissing
+	optionalOrMissing := All(Optional(IsString), KeyMissing)
+	assert.True(t, optionalOrMissing.Check(p, nil, false).Valid)
+	assert.False(t, optionalOrMissing.Check(p, "x", true).Valid)
+
+	impossible := All(KeyMissing, IsString)
+	assert.False(t, impossible.Check(p, nil, false).Valid)
+	assert.False(t, impossible.Check(p, "x", true).Valid)
+}
+
+func TestNot(t *testing.T) {
+	id := Not(IsEqual("foo"))
+
+	assertIsDefValid(t, id, "bar")
+	res := assertIsDefInvalid(t, id, "foo")
+	assert.Contains(t, res.Errors()[0].Error(), "should not have matched")
+
+	p := llpath.MustParsePath("p")
+	// Not requires the key, even though the wrapped definition fails on a missing key
+	assert.False(t, id.Optional)
+	assert.False(t, id.Check(p, nil, false).Valid)
+	assert.True(t, Optional(id).Check(p, nil, false).Valid)
+
+	present := Not(KeyMissing)
+	assert.False(t, present.Check(p, nil, false).Valid)
+	assert.True(t, present.Check(p, "x", true).Valid)
+
+	notOptional := Not(Optional(IsString))
+	assert.False(t, notOptional.Optional)
+	assert.False(t, notOptional.Check(p, nil, false).Valid)
+	assert.True(t, notOptional.Check(p, 1, true).Valid)
+
+	// Requiring presence and negating the value
+	presentNotFoo := All(KeyPresent, Not(IsEqual("foo")))
+	assert.False(t, presentNotFoo.Check(p, nil, false).Valid)
+	assert.True(t, presentNotFoo.Check(p, "bar", true).Valid)
+}
+
+func TestExactlyOne(t *testing.T) {
+	id := ExactlyOne(IsStringContaining("a"), IsStringContaining("b"))
+
+	assertIsDefValid(t, id, "a")
+	assertIsDefValid(t, id, "b")
+
+	res := assertIsDefInvalid(t, id, "ab")
+	assert.Contains(t, res.Errors()[0].Error(), "expected exactly one")
+
+	res = assertIsDefInvalid(t, id, "c")
+	require.Len(t, res.Errors(), 3)
+	assert.Contains(t, res.Errors()[1].Error(), "did not contain substring 'a'")
+	assert.Contains(t, res.Errors()[2].Error(), "did not contain substring 'b'")
+
+	p := llpath.MustParsePath("p")
+	assert.False(t, id.Check(p, nil, false).Valid)
+
+	orMissing := ExactlyOne(KeyMissing, IsString)
+	assert.True(t, orMissing.Check(p, nil, false).Valid)
+	assert.True(t, orMissing.Check(p, "x", true).Valid)
+	assert.False(t, orMissing.Check(p, 1, true).Valid)
+}
+
+func TestIsSchema(t *testing.T) {
+	id := IsSchema(keyValidator("foo", IsEqual("bar")))
+
+	res := assertIsDefValid(t, id, map[string]interface{}{"foo": "bar"})
+	assert.Contains(t, res.Fields, "p.foo")
+
+	res = assertIsDefInvalid(t, id, map[string]interface{}{"foo": "baz"})
+	assert.False(t, res.Fields["p.foo"][0].Valid)
+
+	assertIsDefInvalid(t, id, "a string")
+}
@@ -0,0 +1,210 @@
+package isdef
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+	"github.com/elastic/go-lookslike/validator"
+)
+
+// A Decoder turns the contents of a string into a value that can be validated.
+type Decoder func(s string) (interface{}, error)
+
+// IsDecodedString decodes the string at the given path with the given Decoder, then runs the given
+// validator.Validator against the decoded value. The validator's results are recorded under the
+// path of the string, so a nested key "foo" inside a JSON string at "message" is reported as "message.foo".
+func IsDecodedString(name string, decode Decoder, validator validator.Validator) IsDef {
+	return Is(name, func(path llpath.Path, v interface{}) *llresult.Results {
+		strV, errorResults := isStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		decoded, err := decode(strV)
+		if err != nil {
+			return llresult.SimpleResult(path, false, "Could not decode string '%s': %s", strV, err)
+		}
+
+		res := llresult.NewResults()
+		res.MergeUnderPrefix(path, validator(decoded))
+		return res
+	})
+}
+
+// IsJSONString validates the JSON document embedded in a string. See JSONDecoder for the types produced.
+func IsJSONString(validator validator.Validator) IsDef {
+	return IsDecodedString("is a JSON string", JSONDecoder, validator)
+}
+
+// IsBase64String validates the string decoded from a base64 encoded string. See Base64Decoder for the
+// accepted encodings.
+func IsBase64String(validator validator.Validator) IsDef {
+	return IsDecodedString("is a base64 string", Base64Decoder, validator)
+}
+
+// IsURLQueryString validates the map decoded from a URL query string. See URLQueryDecoder for the
+// types produced.
+func IsURLQueryString(validator validator.Validator) IsDef {
+	return IsDecodedString("is a URL query string", URLQueryDecoder, validator)
+}
+
+// IsKeyValueString validates the map decoded from a string of key=value pairs. See KeyValueDecoder for the
+// accepted syntax.
+func IsKeyValueString(validator validator.Validator) IsDef {
+	return IsDecodedString("is a key=value string", KeyValueDecoder, validator)
+}
+
+// ChainDecoders runs each Decoder on the output of the previous one. Every Decoder but the first must
+// produce a string, e.g. ChainDecoders(Base64Decoder, JSONDecoder) decodes base64 encoded JSON.
+func ChainDecoders(decoders ...Decoder) Decoder {
+	return func(s string) (interface{}, error) {
+		var out interface{} = s
+		for idx, decode := range decoders {
+			str, ok := out.(string)
+			if !ok {
+				return nil, fmt.Errorf("decoder %d produced a %T, expected a string", idx-1, out)
+			}
+
+			var err error
+			out, err = decode(str)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+}
+
+// JSONDecoder decodes a JSON document into map[string]interface{}, []interface{} and scalar values.
+// Numbers without a fractional part or exponent that fit in an int are decoded as int, so they
+// compare equal to int literals in schemas; all other numbers are decoded as float64.
+func JSONDecoder(s string) (interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+
+	var out interface{}
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	// More would report false for a trailing '}' or ']', so require the input to end here
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON document")
+	}
+
+	return convertJSONNumbers(out), nil
+}
+
+func convertJSONNumbers(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		for k, mv := range tv {
+			tv[k] = convertJSONNumbers(mv)
+		}
+	case []interface{}:
+		for i, sv := range tv {
+			tv[i] = convertJSONNumbers(sv)
+		}
+	case json.Number:
+		if i, err := strconv.Atoi(tv.String()); err == nil {
+			return i
+		}
+		f, _ := tv.Float64()
+		return f
+	}
+	return v
+}
+
+// Base64Decoder decodes standard or URL-safe base64, with or without padding, into a string.
+func Base64Decoder(s string) (interface{}, error) {
+	encodings := []*base64.Encoding{
+		base64.StdEncoding,
+		base64.RawStdEncoding,
+		base64.URLEncoding,
+		base64.RawURLEncoding,
+	}
+
+	var err error
+	for _, enc := range encodings {
+		var decoded []byte
+		decoded, err = enc.DecodeString(s)
+		if err == nil {
+			return string(decoded), nil
+		}
+	}
+	return nil, err
+}
+
+// URLQueryDecoder decodes a URL query string, with or without a leading '?', into a map[string]interface{}.
+// Keys with a single value map to a string, repeated keys map to a []interface{} of strings.
+func URLQueryDecoder(s string) (interface{}, error) {
+	values, err := url.ParseQuery(strings.TrimPrefix(s, "?"))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]interface{}, len(values))
+	for k, vs := range values {
+		if len(vs) == 1 {
+			out[k] = vs[0]
+			continue
+		}
+		multi := make([]interface{}, len(vs))
+		for i, v := range vs {
+			multi[i] = v
+		}
+		out[k] = multi
+	}
+	return out, nil
+}
+
+// KeyValueDecoder decodes whitespace separated key=value pairs, such as `status=200 msg="not found"`,
+// into a map[string]interface{} of strings. Values may be double quoted using Go string syntax.
+func KeyValueDecoder(s string) (interface{}, error) {
+	out := map[string]interface{}{}
+
+	rest := strings.TrimLeftFunc(s, unicode.IsSpace)
+	for len(rest) > 0 {
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 || strings.IndexFunc(rest[:eq], unicode.IsSpace) >= 0 {
+			return nil, fmt.Errorf("expected key=value at '%s'", rest)
+		}
+		key := rest[:eq]
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value for key '%s': %w", key, err)
+			}
+			value, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+			if len(rest) > 0 && !unicode.IsSpace(rune(rest[0])) {
+				return nil, fmt.Errorf("expected whitespace after quoted value for key '%s'", key)
+			}
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			value = rest[:end]
+			rest = rest[end:]
+		}
+
+		if _, dupe := out[key]; dupe {
+			return nil, fmt.Errorf("duplicate key '%s'", key)
+		}
+		out[key] = value
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,99 @@
+package isdef
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keyValidator builds a validator.Validator checking the given key of a map with the given IsDef.
+func keyValidator(key string, id IsDef) func(interface{}) *llresult.Results {
+	return func(actual interface{}) *llresult.Results {
+		path := llpath.MustParsePath(key)
+		m, ok := actual.(map[string]interface{})
+		if !ok {
+			return llresult.SimpleResult(path, false, "not a map")
+		}
+		v, exists := m[key]
+		return id.Check(path, v, exists)
+	}
+}
+
+func TestIsJSONString(t *testing.T) {
+	id := IsJSONString(keyValidator("count", IsIntGt(1)))
+
+	res := assertIsDefValid(t, id, `{"count": 2, "other": "x"}`)
+	assert.Contains(t, res.Fields, "p.count")
+
+	res = assertIsDefInvalid(t, id, `{"count": 1}`)
+	require.Contains(t, res.Fields, "p.count")
+	assert.False(t, res.Fields["p.count"][0].Valid)
+
+	res = assertIsDefInvalid(t, id, `{"count": 1.5}`)
+	assert.False(t, res.Fields["p.count"][0].Valid)
+
+	res = assertIsDefInvalid(t, id, `{"count": `)
+	assert.Contains(t, res.Fields, "p")
+	assertIsDefInvalid(t, id, `{} {}`)
+	assertIsDefInvalid(t, id, `{"count": 2}}`)
+	assertIsDefInvalid(t, id, `{"count": 2}]`)
+	assertIsDefValid(t, id, "{\"count\": 2}\n")
+	assertIsDefInvalid(t, id, 123)
+}
+
+func TestIsBase64String(t *testing.T) {
+	id := IsBase64String(func(actual interface{}) *llresult.Results {
+		return IsEqual("hello?").Check(llpath.Path{}, actual, true)
+	})
+
+	assertIsDefValid(t, id, base64.StdEncoding.EncodeToString([]byte("hello?")))
+	assertIsDefValid(t, id, base64.RawURLEncoding.EncodeToString([]byte("hello?")))
+	res := assertIsDefInvalid(t, id, base64.StdEncoding.EncodeToString([]byte("bye")))
+	assert.Contains(t, res.Fields, "p")
+	assertIsDefInvalid(t, id, "not base64!")
+}
+
+func TestIsURLQueryString(t *testing.T) {
+	id := IsURLQueryString(keyValidator("q", IsEqual("lookslike")))
+
+	assertIsDefValid(t, id, "q=lookslike&page=2")
+	assertIsDefValid(t, id, "?q=lookslike")
+	assertIsDefInvalid(t, id, "q=other")
+	assertIsDefInvalid(t, id, "q=%zz")
+
+	multi := IsURLQueryString(keyValidator("tag", IsEqual([]interface{}{"a", "b"})))
+	assertIsDefValid(t, multi, "tag=a&tag=b")
+}
+
+func TestIsKeyValueString(t *testing.T) {
+	id := IsKeyValueString(keyValidator("msg", IsEqual("not found")))
+
+	assertIsDefValid(t, id, `status=404 msg="not found"`)
+	assertIsDefValid(t, id, `  msg="not found"  `)
+	assertIsDefInvalid(t, id, `status=404 msg=found`)
+	assertIsDefInvalid(t, id, `status 404`)
+	assertIsDefInvalid(t, id, `msg="unterminated`)
+	assertIsDefInvalid(t, id, `msg=a msg=b`)
+}
+
+func TestChainDecoders(t *testing.T) {
+	id := IsDecodedString(
+		"is base64 JSON",
+		ChainDecoders(Base64Decoder, JSONDecoder),
+		keyValidator("ok", IsEqual(true)),
+	)
+
+	assertIsDefValid(t, id, base64.StdEncoding.EncodeToString([]byte(`{"ok": true}`)))
+	assertIsDefInvalid(t, id, base64.StdEncoding.EncodeToString([]byte(`{"ok": false}`)))
+
+	notAString := IsDecodedString(
+		"is JSON twice",
+		ChainDecoders(JSONDecoder, JSONDecoder),
+		keyValidator("ok", IsEqual(true)),
+	)
+	assertIsDefInvalid(t, notAString, `{"ok": true}`)
+}
@@ -0,0 +1,241 @@
+package isdef
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+)
+
+// valueDiff is a single difference found by deepDiff.
+type valueDiff struct {
+	// path is where the difference is, relative to the compared values. It only extends through maps with
+	// string keys and slices, so that it can be recorded in Results.
+	path llpath.Path
+	// detail locates the difference below path for values a Path cannot express, like struct fields, e.g. `.Port`.
+	detail string
+	msg    string
+}
+
+type visit struct {
+	actual, expected uintptr
+	typ              reflect.Type
+}
+
+// differ walks two values the way reflect.DeepEqual does, collecting their differences.
+type differ struct {
+	diffs   []valueDiff
+	visited map[visit]bool
+}
+
+// deepDiff returns the differences between actual and expected, in a stable order. It returns no differences
+// exactly when reflect.DeepEqual would return true.
+func deepDiff(actual, expected interface{}) []valueDiff {
+	d := &differ{visited: map[visit]bool{}}
+	d.diff(llpath.Path{}, "", reflect.ValueOf(actual), reflect.ValueOf(expected))
+	return d.diffs
+}
+
+func (d *differ) add(path llpath.Path, detail string, msg string, args ...interface{}) {
+	d.diffs = append(d.diffs, valueDiff{path, detail, fmt.Sprintf(msg, args...)})
+}
+
+func (d *differ) diff(path llpath.Path, detail string, actual, expected reflect.Value) {
+	if !actual.IsValid() || !expected.IsValid() {
+		if actual.IsValid() != expected.IsValid() {
+			d.add(path, detail, "actual(%s) != expected(%s)", describe(actual), describe(expected))
+		}
+		return
+	}
+	if actual.Type() != expected.Type() {
+		d.add(path, detail, "actual(%s) != expected(%s)", describe(actual), describe(expected))
+		return
+	}
+
+	switch expected.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Ptr:
+		if actual.IsNil() != expected.IsNil() {
+			d.add(path, detail, "actual(%s) != expected(%s)", describe(actual), describe(expected))
+			return
+		}
+		if actual.Pointer() == expected.Pointer() && (expected.Kind() != reflect.Slice || actual.Len() == expected.Len()) {
+			return
+		}
+		// Guard against cycles, as reflect.DeepEqual does
+		v := visit{actual.Pointer(), expected.Pointer(), expected.Type()}
+		if d.visited[v] {
+			return
+		}
+		d.visited[v] = true
+	}
+
+	switch expected.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		d.diff(path, detail, actual.Elem(), expected.Elem())
+	case reflect.Map:
+		d.diffMap(path, detail, actual, expected)
+	case reflect.Slice, reflect.Array:
+		d.diffSlice(path, detail, actual, expected)
+	case reflect.Struct:
+		for i := 0; i < expected.NumField(); i++ {
+			name := expected.Type().Field(i).Name
+			d.diff(path, detail+"."+name, actual.Field(i), expected.Field(i))
+		}
+	case reflect.Func:
+		// Like reflect.DeepEqual, functions are only equal when both are nil
+		if !actual.IsNil() || !expected.IsNil() {
+			d.add(path, detail, "functions are only equal when both are nil")
+		}
+	default:
+		if !leafEqual(actual, expected) {
+			d.add(path, detail, "actual(%s) != expected(%s)", describe(actual), describe(expected))
+		}
+	}
+}
+
+func (d *differ) diffMap(path llpath.Path, detail string, actual, expected reflect.Value) {
+	keys := append(expected.MapKeys(), actual.MapKeys()...)
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprintf("%#v", keys[i]) < fmt.Sprintf("%#v", keys[j])
+	})
+
+	seen := map[string]bool{}
+	for _, k := range keys {
+		keyStr := fmt.Sprintf("%#v", k)
+		if seen[keyStr] {
+			continue
+		}
+		seen[keyStr] = true
+
+		childPath, childDetail := path, detail
+		if key, ok := pathKey(k); ok && detail == "" {
+			childPath = path.ExtendMap(key)
+		} else {
+			childDetail = fmt.Sprintf("%s[%s]", detail, keyStr)
+		}
+
+		av, ev := actual.MapIndex(k), expected.MapIndex(k)
+		switch {
+		case !ev.IsValid():
+			d.add(childPath, childDetail, "unexpected key, actual(%s)", describe(av))
+		case !av.IsValid():
+			d.add(childPath, childDetail, "missing key, expected(%s)", describe(ev))
+		default:
+			d.diff(childPath, childDetail, av, ev)
+		}
+	}
+}
+
+func (d *differ) diffSlice(path llpath.Path, detail string, actual, expected reflect.Value) {
+	for i := 0; i < actual.Len() || i < expected.Len(); i++ {
+		childPath, childDetail := path, detail
+		if detail == "" {
+			childPath = path.ExtendSlice(i)
+		} else {
+			childDetail = fmt.Sprintf("%s[%d]", detail, i)
+		}
+
+		switch {
+		case i >= expected.Len():
+			d.add(childPath, childDetail, "unexpected element, actual(%s)", describe(actual.Index(i)))
+		case i >= actual.Len():
+			d.add(childPath, childDetail, "missing element, expected(%s)", describe(expected.Index(i)))
+		default:
+			d.diff(childPath, childDetail, actual.Index(i), expected.Index(i))
+		}
+	}
+}
+
+// pathKey returns the map key as a Path key, if it is a string that parses back to the same single key.
+func pathKey(k reflect.Value) (string, bool) {
+	if k.Kind() == reflect.Interface {
+		k = k.Elem()
+	}
+	if k.Kind() != reflect.String {
+		return "", false
+	}
+	p, err := llpath.ParsePath(k.String())
+	if err != nil || len(p) != 1 || p[0].Key != k.String() {
+		return "", false
+	}
+	return k.String(), true
+}
+
+// leafEqual compares values that have no elements to recurse into. It works on unexported struct fields too.
+func leafEqual(actual, expected reflect.Value) bool {
+	switch expected.Kind() {
+	case reflect.Bool:
+		return actual.Bool() == expected.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return actual.Int() == expected.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return actual.Uint() == expected.Uint()
+	case reflect.Float32, reflect.Float64:
+		return actual.Float() == expected.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return actual.Complex() == expected.Complex()
+	case reflect.String:
+		return actual.String() == expected.String()
+	case reflect.Chan, reflect.UnsafePointer:
+		return actual.Pointer() == expected.Pointer()
+	}
+	return false
+}
+
+// describe formats a value with its type, like %T(%v) does for interfaces.
+func describe(v reflect.Value) string {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%s(%v)", v.Type(), v)
+}
+
+// deepEqualResults reports the differences between actual and expected. When the values are maps or slices
+// each difference is recorded at its own path below path, otherwise they are all described at path.
+func deepEqualResults(path llpath.Path, actual, expected interface{}) *llresult.Results {
+	diffs := deepDiff(actual, expected)
+	if len(diffs) == 0 {
+		// Should not happen, but keep the original message rather than claiming the values are equal
+		return llresult.SimpleResult(
+			path,
+			false,
+			"objects not equal: actual(%T(%v)) != expected(%T(%v))", actual, actual, expected, expected,
+		)
+	}
+
+	res := llresult.NewResults()
+	var atPath []string
+	for _, diff := range diffs {
+		msg := diff.msg
+		if diff.detail != "" {
+			msg = fmt.Sprintf("%s: %s", diff.detail, msg)
+		}
+		if len(diff.path) == 0 {
+			atPath = append(atPath, msg)
+			continue
+		}
+		res.Record(path.Concat(diff.path), llresult.ValueResult{
+			Valid:   false,
+			Message: "objects not equal: " + msg,
+		})
+	}
+
+	if len(atPath) > 0 {
+		res.Record(path, llresult.ValueResult{
+			Valid:   false,
+			Message: "objects not equal: " + strings.Join(atPath, "; "),
+		})
+	} else {
+		res.Record(path, llresult.ValueResult{
+			Valid:   false,
+			Message: fmt.Sprintf("objects not equal: %d difference(s) found below this path", len(diffs)),
+		})
+	}
+	return res
+}
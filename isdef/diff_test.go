@@ -0,0 +1,91 @@
+package isdef
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type diffTestEndpoint struct {
+	Host string
+	Port int
+	tags []string
+}
+
+func TestIsDeepEqualDiffPaths(t *testing.T) {
+	expected := map[string]interface{}{
+		"name":  "web",
+		"ports": []int{80, 443},
+		"meta": map[string]interface{}{
+			"owner":   "ops",
+			"retries": 3,
+		},
+		"a.b": 1,
+	}
+	actual := map[string]interface{}{
+		"name":  "web",
+		"ports": []int{80, 8443, 9000},
+		"meta": map[string]interface{}{
+			"owner": "dev",
+			"extra": true,
+		},
+		"a.b": 2,
+	}
+
+	res := assertIsDefInvalid(t, IsDeepEqual(expected), actual)
+
+	assert.Equal(t, "objects not equal: actual(int(8443)) != expected(int(443))", res.Fields["p.ports.[1]"][0].Message)
+	assert.Equal(t, "objects not equal: unexpected element, actual(int(9000))", res.Fields["p.ports.[2]"][0].Message)
+	assert.Equal(t, "objects not equal: actual(string(dev)) != expected(string(ops))", res.Fields["p.meta.owner"][0].Message)
+	assert.Equal(t, "objects not equal: unexpected key, actual(bool(true))", res.Fields["p.meta.extra"][0].Message)
+	assert.Equal(t, "objects not equal: missing key, expected(int(3))", res.Fields["p.meta.retries"][0].Message)
+	assert.NotContains(t, res.Fields, "p.name")
+
+	// Keys that cannot be expressed as a path are described at the parent
+	require.Len(t, res.Fields["p"], 1)
+	assert.Equal(t, `objects not equal: ["a.b"]: actual(int(2)) != expected(int(1))`, res.Fields["p"][0].Message)
+}
+
+func TestIsDeepEqualDiffStructs(t *testing.T) {
+	expected := diffTestEndpoint{Host: "localhost", Port: 80, tags: []string{"a"}}
+
+	res := assertIsDefInvalid(t, IsDeepEqual(expected), diffTestEndpoint{Host: "localhost", Port: 8080, tags: []string{"a", "b"}})
+	require.Len(t, res.Fields, 1)
+	assert.Equal(
+		t,
+		"objects not equal: .Port: actual(int(8080)) != expected(int(80)); .tags[1]: unexpected element, actual(string(b))",
+		res.Fields["p"][0].Message,
+	)
+
+	// Struct differences inside a slice are recorded at the element
+	res = assertIsDefInvalid(t, IsDeepEqual([]*diffTestEndpoint{&expected}), []*diffTestEndpoint{{Host: "remote", Port: 80, tags: []string{"a"}}})
+	assert.Equal(t, "objects not equal: .Host: actual(string(remote)) != expected(string(localhost))", res.Fields["p.[0]"][0].Message)
+	assert.Equal(t, "objects not equal: 1 difference(s) found below this path", res.Fields["p"][0].Message)
+
+	res = assertIsDefInvalid(t, IsDeepEqual(expected), "localhost:80")
+	assert.Equal(t, "objects not equal: actual(string(localhost:80)) != expected(isdef.diffTestEndpoint({localhost 80 [a]}))", res.Fields["p"][0].Message)
+}
+
+func TestDeepDiffMatchesDeepEqual(t *testing.T) {
+	type cyclic struct {
+		Next *cyclic
+		V    int
+	}
+	c1 := &cyclic{V: 1}
+	c1.Next = c1
+	c2 := &cyclic{V: 1}
+	c2.Next = c2
+
+	values := []interface{}{
+		nil, 1, 1.0, "1", []int(nil), []int{}, []int{1}, map[string]int(nil), map[string]int{},
+		map[int]string{1: "a"}, map[int]string{1: "b"}, [2]int{1, 2}, [2]int{1, 3},
+		diffTestEndpoint{Port: 1}, &diffTestEndpoint{Port: 1}, c1, c2, &cyclic{V: 2},
+	}
+	for _, a := range values {
+		for _, e := range values {
+			assert.Equal(t, reflect.DeepEqual(a, e), len(deepDiff(a, e)) == 0, "%#v vs %#v", a, e)
+		}
+	}
+}
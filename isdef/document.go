@@ -0,0 +1,144 @@
+package isdef
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+)
+
+// Document is the whole value passed to a validator.Validator. It gives a DocumentValidator access to
+// values outside of the path being checked. The zero Document contains nothing, which is what IsDefs
+// see when checked outside of a validator.Validator.
+type Document struct {
+	root    interface{}
+	hasRoot bool
+}
+
+// NewDocument creates a Document for the given root value.
+func NewDocument(root interface{}) Document {
+	return Document{root: root, hasRoot: true}
+}
+
+// A DocumentValidator is a ValueValidator that also receives the Document containing the value.
+type DocumentValidator func(doc Document, path llpath.Path, v interface{}) *llresult.Results
+
+// IsInDocument creates a named IsDef whose checker also receives the Document being validated, so it can
+// compare the value against other fields. The checker may record results on any path, not only its own.
+// IsAny, All, Not and ExactlyOne pass the Document through to the IsDefs they combine. Nested validators,
+// such as those given to IsSchema or IsSliceOf, see the nested value as their whole Document.
+func IsInDocument(name string, checker DocumentValidator) IsDef {
+	return IsDef{
+		Name:            name,
+		DocumentChecker: checker,
+		Checker: func(path llpath.Path, v interface{}) *llresult.Results {
+			return checker(Document{}, path, v)
+		},
+	}
+}
+
+// Get returns the value at the given absolute path within the Document.
+func (d Document) Get(path llpath.Path) (value interface{}, exists bool) {
+	if !d.hasRoot {
+		return nil, false
+	}
+
+	val, exists := path.GetFrom(reflect.ValueOf(d.root))
+	if !exists || !val.IsValid() {
+		return nil, exists
+	}
+	return val.Interface(), true
+}
+
+// Resolve turns a reference into an absolute path. A reference without leading dots is an absolute path
+// within the Document. A reference with leading dots is relative to the given path: one dot refers to a
+// sibling and each extra dot goes up one more level. From "event.end", ".start" resolves to "event.start"
+// and "..host.name" resolves to "host.name".
+func (d Document) Resolve(from llpath.Path, ref string) (llpath.Path, error) {
+	trimmed := strings.TrimLeft(ref, ".")
+	up := len(ref) - len(trimmed)
+
+	rel, err := llpath.ParsePath(trimmed)
+	if err != nil {
+		return nil, err
+	}
+	if up == 0 {
+		return rel, nil
+	}
+	if up > len(from) {
+		return nil, fmt.Errorf("reference '%s' goes above the root of the document from '%s'", ref, from)
+	}
+	return from[:len(from)-up].Concat(rel), nil
+}
+
+// IsFieldRelation checks the value at the current path against the value of another field in the same
+// Document, referenced as described by Document.Resolve. The given function returns a non-nil error
+// describing why the pair of values is invalid. The check fails if the other field does not exist.
+func IsFieldRelation(name string, ref string, fn func(v, other interface{}) error) IsDef {
+	return IsInDocument(name, func(doc Document, path llpath.Path, v interface{}) *llresult.Results {
+		otherPath, err := doc.Resolve(path, ref)
+		if err != nil {
+			return llresult.SimpleResult(path, false, fmt.Sprintf("Invalid field reference: %s", err))
+		}
+
+		other, exists := doc.Get(otherPath)
+		if !exists {
+			return llresult.SimpleResult(
+				path,
+				false,
+				fmt.Sprintf("Referenced field '%s' is not present in the document", otherPath),
+			)
+		}
+
+		if err := fn(v, other); err != nil {
+			return llresult.SimpleResult(path, false, fmt.Sprintf("%s (compared to '%s')", err, otherPath))
+		}
+		return llresult.ValidResult(path)
+	})
+}
+
+// IsEqualToField checks that the value is deeply equal to the value of the referenced field.
+func IsEqualToField(ref string) IsDef {
+	return IsFieldRelation("equal to field "+ref, ref, func(v, other interface{}) error {
+		if !reflect.DeepEqual(v, other) {
+			return fmt.Errorf("objects not equal: actual(%T(%v)) != field(%T(%v))", v, v, other, other)
+		}
+		return nil
+	})
+}
+
+func fieldComparison(name string, ref string, symbol string, valid func(cmp int) bool) IsDef {
+	return IsFieldRelation(name+" field "+ref, ref, func(v, other interface{}) error {
+		cmp, err := compareValues(v, other)
+		if err != nil {
+			return err
+		}
+		if !valid(cmp) {
+			return fmt.Errorf("%v is not %s %v", v, symbol, other)
+		}
+		return nil
+	})
+}
+
+// IsGtField checks that the value is greater than the value of the referenced field. Numbers, strings
+// and time.Times can be compared.
+func IsGtField(ref string) IsDef {
+	return fieldComparison("greater than", ref, ">", func(cmp int) bool { return cmp > 0 })
+}
+
+// IsGteField checks that the value is greater than or equal to the value of the referenced field.
+func IsGteField(ref string) IsDef {
+	return fieldComparison("greater than or equal to", ref, ">=", func(cmp int) bool { return cmp >= 0 })
+}
+
+// IsLtField checks that the value is less than the value of the referenced field.
+func IsLtField(ref string) IsDef {
+	return fieldComparison("less than", ref, "<", func(cmp int) bool { return cmp < 0 })
+}
+
+// IsLteField checks that the value is less than or equal to the value of the referenced field.
+func IsLteField(ref string) IsDef {
+	return fieldComparison("less than or equal to", ref, "<=", func(cmp int) bool { return cmp <= 0 })
+}
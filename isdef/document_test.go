@@ -0,0 +1,88 @@
+package isdef
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentResolve(t *testing.T) {
+	doc := NewDocument(nil)
+	from := llpath.MustParsePath("event.end")
+
+	cases := map[string]string{
+		"event.start":  "event.start",
+		".start":       "event.start",
+		"..host.name":  "host.name",
+		".nested.[0]":  "event.nested.[0]",
+		"..":           "",
+		"..event.kind": "event.kind",
+	}
+	for ref, expected := range cases {
+		resolved, err := doc.Resolve(from, ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, expected, resolved.String(), ref)
+	}
+
+	_, err := doc.Resolve(from, "...too.far")
+	assert.Error(t, err)
+	_, err = doc.Resolve(from, ".a..b")
+	assert.Error(t, err)
+}
+
+func TestDocumentGet(t *testing.T) {
+	doc := NewDocument(map[string]interface{}{
+		"a": map[string]interface{}{"b": 1, "n": nil},
+	})
+
+	v, ok := doc.Get(llpath.MustParsePath("a.b"))
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	_, ok = doc.Get(llpath.MustParsePath("a.c"))
+	assert.False(t, ok)
+
+	_, ok = Document{}.Get(llpath.MustParsePath("a.b"))
+	assert.False(t, ok)
+}
+
+func TestFieldComparisons(t *testing.T) {
+	start := time.Now()
+	doc := NewDocument(map[string]interface{}{
+		"event": map[string]interface{}{"start": start, "count": 3},
+	})
+	p := llpath.MustParsePath("event.end")
+
+	assert.True(t, IsGteField(".start").CheckInDocument(doc, p, start, true).Valid)
+	assert.True(t, IsGtField(".start").CheckInDocument(doc, p, start.Add(time.Second), true).Valid)
+	assert.False(t, IsGtField(".start").CheckInDocument(doc, p, start, true).Valid)
+	assert.True(t, IsLtField("event.count").CheckInDocument(doc, p, 2.5, true).Valid)
+	assert.False(t, IsLteField("event.count").CheckInDocument(doc, p, uint(4), true).Valid)
+	assert.True(t, IsEqualToField(".count").CheckInDocument(doc, p, 3, true).Valid)
+
+	res := IsGtField(".start").CheckInDocument(doc, p, "not a time", true)
+	assert.False(t, res.Valid)
+	assert.Contains(t, res.Fields["event.end"][0].Message, "cannot order")
+
+	res = IsGtField(".missing").CheckInDocument(doc, p, 1, true)
+	assert.Contains(t, res.Fields["event.end"][0].Message, "'event.missing' is not present")
+
+	// Outside of a document there is nothing to compare against
+	assertIsDefInvalid(t, IsEqualToField(".start"), start)
+}
+
+func TestDocumentPassedThroughCombinators(t *testing.T) {
+	doc := NewDocument(map[string]interface{}{"min": 1, "max": 10})
+	p := llpath.MustParsePath("value")
+	inRange := All(IsGteField(".min"), IsLteField(".max"))
+
+	assert.True(t, inRange.CheckInDocument(doc, p, 5, true).Valid)
+	assert.False(t, inRange.CheckInDocument(doc, p, 11, true).Valid)
+	assert.True(t, Not(inRange).CheckInDocument(doc, p, 0, true).Valid)
+	assert.True(t, IsAny(IsEqual("n/a"), inRange).CheckInDocument(doc, p, 3, true).Valid)
+	assert.False(t, ExactlyOne(IsEqual(3), inRange).CheckInDocument(doc, p, 11, true).Valid)
+	assert.True(t, Optional(inRange).CheckInDocument(doc, p, 2, true).Valid)
+}
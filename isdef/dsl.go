@@ -3,6 +3,8 @@ package isdef
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/elastic/go-lookslike/internal/llreflect"
 	"github.com/elastic/go-lookslike/llpath"
@@ -20,16 +22,48 @@ type ValueValidator func(path llpath.Path, v interface{}) *llresult.Results
 
 // An IsDef defines the type of Check to do.
 // Generally only Name and Checker are set. Optional and CheckKeyMissing are
-// needed for weird checks like key presence.
+// needed for weird checks like key presence. DocumentChecker takes precedence over
+// Checker and is set for checks that need the rest of the document, see IsInDocument.
+// Spec is set by the built-in IsDefs to describe how they were created.
 type IsDef struct {
 	Name            string
 	Checker         ValueValidator
+	DocumentChecker DocumentValidator
 	Optional        bool
 	CheckKeyMissing bool
+	Spec            *Spec
+}
+
+// Spec describes which built-in matcher created an IsDef and with which arguments, so tools such as the
+// jsonschema exporter can describe a schema without running it. Matcher is the name of the constructor
+// in lower camel case, e.g. "isStringMatching", and Args are the arguments it was called with. Combinators
+// such as IsAny have their IsDefs as Args. IsDefs created with Is or IsInDocument have no Spec.
+type Spec struct {
+	Matcher string
+	Args    []interface{}
+}
+
+func (id IsDef) withSpec(matcher string, args ...interface{}) IsDef {
+	id.Spec = &Spec{Matcher: matcher, Args: args}
+	return id
+}
+
+// toArgs converts variadic arguments of any type into Spec.Args.
+func toArgs[T any](values []T) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
 }
 
 // Check runs the IsDef at the given value at the given path
 func (id IsDef) Check(path llpath.Path, v interface{}, keyExists bool) *llresult.Results {
+	return id.CheckInDocument(Document{}, path, v, keyExists)
+}
+
+// CheckInDocument runs the IsDef at the given value at the given path within the given Document.
+func (id IsDef) CheckInDocument(doc Document, path llpath.Path, v interface{}, keyExists bool) *llresult.Results {
 	if id.CheckKeyMissing {
 		if !keyExists {
 			return llresult.ValidResult(path)
@@ -38,10 +72,17 @@ func (id IsDef) Check(path llpath.Path, v interface{}, keyExists bool) *llresult
 		return llresult.SimpleResult(path, false, "this key should not exist")
 	}
 
-	if !id.Optional && !keyExists {
+	if !keyExists {
+		if id.Optional {
+			return llresult.ValidResult(path)
+		}
 		return llresult.KeyMissingResult(path)
 	}
 
+	if id.DocumentChecker != nil {
+		return id.DocumentChecker(doc, path, v)
+	}
+
 	if id.Checker != nil {
 		return id.Checker(path, v)
 	}
@@ -56,6 +97,44 @@ func Optional(id IsDef) IsDef {
 	return id
 }
 
+// Sensitive wraps an IsDef so the value it checks is masked in failure messages, and marked as sensitive in
+// the results so that an llresult.Redaction, like the one testslike uses, masks it when the validated value is
+// printed. The elements of maps and slices are masked individually. Text coming from the wrapped IsDef's own
+// arguments, such as an expected value, is left as is.
+func Sensitive(def IsDef) IsDef {
+	keep := specValues(def)
+	id := IsInDocument(def.Name, func(doc Document, path llpath.Path, v interface{}) *llresult.Results {
+		res := def.CheckInDocument(doc, path, v, true)
+		redacted := llresult.NewResults()
+		res.EachResult(func(p llpath.Path, vr llresult.ValueResult) bool {
+			vr.Message = llresult.RedactMessage(vr.Message, v, keep...)
+			redacted.Record(p, vr)
+			return true
+		})
+		redacted.MarkSensitive(path)
+		return redacted
+	})
+	id.Optional = def.Optional
+	id.CheckKeyMissing = def.CheckKeyMissing
+	return id.withSpec("sensitive", def)
+}
+
+// specValues returns the arguments def was built with, including those of IsDefs it was built from.
+func specValues(def IsDef) []interface{} {
+	if def.Spec == nil {
+		return nil
+	}
+	var values []interface{}
+	for _, arg := range def.Spec.Args {
+		if inner, ok := arg.(IsDef); ok {
+			values = append(values, specValues(inner)...)
+		} else {
+			values = append(values, arg)
+		}
+	}
+	return values
+}
+
 // IsSliceOf validates that the array at the given key is an array of objects all validatable
 // via the given validator.Validator.
 func IsSliceOf(validator validator.Validator) IsDef {
@@ -74,59 +153,253 @@ func IsSliceOf(validator validator.Validator) IsDef {
 		}
 
 		return res
-	})
+	}).withSpec("isSliceOf", validator)
+}
+
+// IsSchema wraps a validator.Validator, usually created with lookslike.MustCompile, as an IsDef applied to
+// the value at the current path. The validator's results are recorded under that path. This lets nested schemas be
+// combined with IsAny, Optional and other IsDefs, which is not possible with a plain nested map.
+func IsSchema(validator validator.Validator) IsDef {
+	return Is("matches schema", func(path llpath.Path, v interface{}) *llresult.Results {
+		res := llresult.NewResults()
+		res.MergeUnderPrefix(path, validator(v))
+		return res
+	}).withSpec("isSchema", validator)
 }
 
 // IsAny takes a variable number of IsDef's and combines them with a logical OR. If any single definition
 // matches the key will be marked as valid.
+// If none match, the failure messages of every definition are recorded at the key, and the results of the
+// closest matching definition are merged in so failures within nested values are reported at their own paths.
 func IsAny(of ...IsDef) IsDef {
-	names := make([]string, len(of))
-	for i, def := range of {
-		names[i] = def.Name
-	}
+	names := defNames(of)
 	isName := fmt.Sprintf("either %#v", names)
 
-	return Is(isName, func(path llpath.Path, v interface{}) *llresult.Results {
+	id := IsInDocument(isName, func(doc Document, path llpath.Path, v interface{}) *llresult.Results {
+		failures := make([]branchFailure, 0, len(of))
 		for _, def := range of {
-			vr := def.Check(path, v, true)
+			vr := def.CheckInDocument(doc, path, v, true)
 			if vr.Valid {
 				return vr
 			}
+			failures = append(failures, branchFailure{def, vr})
 		}
 
-		return llresult.SimpleResult(
+		return noneMatchedResults(
 			path,
-			false,
 			fmt.Sprintf("Value was none of %#v, actual value was %#v", names, v),
+			failures,
 		)
 	})
+	return id.withSpec("isAny", toArgs(of)...)
+}
+
+// validWhenMissing returns true if the IsDef passes when its key is not present.
+func validWhenMissing(def IsDef) bool {
+	return def.Optional || def.CheckKeyMissing
+}
+
+// lengthBetween reports whether length is between min and max inclusive, where a negative max means there is
+// no upper bound. If it is not, expected describes the allowed lengths, e.g. "between 1 and 3".
+func lengthBetween(length, min, max int) (expected string, ok bool) {
+	if length >= min && (max < 0 || length <= max) {
+		return "", true
+	}
+
+	switch {
+	case min == max:
+		return fmt.Sprintf("%d", min), false
+	case max < 0:
+		return fmt.Sprintf("at least %d", min), false
+	case min <= 0:
+		return fmt.Sprintf("at most %d", max), false
+	}
+	return fmt.Sprintf("between %d and %d", min, max), false
+}
+
+func defNames(defs []IsDef) []string {
+	names := make([]string, len(defs))
+	for i, def := range defs {
+		names[i] = def.Name
+	}
+	return names
+}
+
+// branchFailure holds the results of a definition that did not match, as used by IsAny and ExactlyOne.
+type branchFailure struct {
+	def IsDef
+	res *llresult.Results
+}
+
+// closeness scores how nearly the branch matched as the fraction of its results that were valid.
+func (bf branchFailure) closeness() (score float64, invalid int) {
+	valid := 0
+	bf.res.EachResult(func(_ llpath.Path, vr llresult.ValueResult) bool {
+		if vr.Valid {
+			valid++
+		} else {
+			invalid++
+		}
+		return true
+	})
+	if valid+invalid == 0 {
+		return 0, 0
+	}
+	return float64(valid) / float64(valid+invalid), invalid
 }
 
-// IsUnique instances are used in multiple spots, flagging a value as being in error if it's seen across invocations.
-// To use it, assign IsUnique to a variable, then use that variable multiple times in a map[string]interface{}.
-func IsUnique() IsDef {
-	return ScopedIsUnique().IsUniqueTo("")
+// summary renders the failed results of the branch on one line. Failures below path are prefixed
+// with their path relative to it.
+func (bf branchFailure) summary(path llpath.Path) string {
+	prefix := path.String()
+	msgs := []string{}
+	for p, vrs := range bf.res.Fields {
+		rel := p
+		if len(prefix) > 0 {
+			rel = strings.TrimPrefix(strings.TrimPrefix(p, prefix), ".")
+		}
+		for _, vr := range vrs {
+			if vr.Valid {
+				continue
+			}
+			if rel == "" {
+				msgs = append(msgs, vr.Message)
+			} else {
+				msgs = append(msgs, fmt.Sprintf("@%s: %s", rel, vr.Message))
+			}
+		}
+	}
+	sort.Strings(msgs)
+	return fmt.Sprintf("'%s' failed: %s", bf.def.Name, strings.Join(msgs, "; "))
 }
 
-// UniqScopeTracker is represents the tracking data for invoking IsUniqueTo.
-type UniqScopeTracker map[interface{}]string
+// noneMatchedResults reports a value that matched none of the given branches. The header and one message per
+// branch are recorded at path, then all results of the closest matching branch that lie below path are merged in.
+func noneMatchedResults(path llpath.Path, header string, failures []branchFailure) *llresult.Results {
+	closest := -1
+	var bestScore float64
+	var bestInvalid int
+	for idx, bf := range failures {
+		score, invalid := bf.closeness()
+		if closest < 0 || score > bestScore || (score == bestScore && invalid < bestInvalid) {
+			closest, bestScore, bestInvalid = idx, score, invalid
+		}
+	}
+
+	if closest >= 0 {
+		header = fmt.Sprintf("%s; closest match was '%s'", header, failures[closest].def.Name)
+	}
+	res := llresult.SimpleResult(path, false, header)
+
+	for idx, bf := range failures {
+		res.Record(path, llresult.ValueResult{
+			Valid:   false,
+			Message: fmt.Sprintf("branch %d %s", idx, bf.summary(path)),
+		})
+	}
 
-// IsUniqueTo validates that the given value is only ever seen within a single namespace.
-func (ust UniqScopeTracker) IsUniqueTo(namespace string) IsDef {
-	return Is("unique", func(path llpath.Path, v interface{}) *llresult.Results {
-		for trackerK, trackerNs := range ust {
-			hasNamespace := len(namespace) > 0
-			if reflect.DeepEqual(trackerK, v) && (!hasNamespace || namespace != trackerNs) {
-				return llresult.SimpleResult(path, false, "Value '%v' is repeated", v)
+	if closest >= 0 {
+		pathStr := path.String()
+		for p, vrs := range failures[closest].res.Fields {
+			if p == pathStr {
+				continue
+			}
+			for _, vr := range vrs {
+				res.Record(llpath.MustParsePath(p), vr)
 			}
 		}
+	}
+
+	return res
+}
+
+// All takes a variable number of IsDef's and combines them with a logical AND. The key is only valid
+// if every definition matches, and the results of every definition are recorded.
+// A missing key is valid only if it is valid for all definitions, i.e. each is Optional or checks that the key
+// is missing. The combined definition checks that the key is missing only if all definitions do.
+func All(of ...IsDef) IsDef {
+	optional := len(of) > 0
+	keyMissing := len(of) > 0
+	for _, def := range of {
+		optional = optional && validWhenMissing(def)
+		keyMissing = keyMissing && def.CheckKeyMissing
+	}
+
+	id := IsInDocument(fmt.Sprintf("all of %#v", defNames(of)), func(doc Document, path llpath.Path, v interface{}) *llresult.Results {
+		res := llresult.NewResults()
+		for _, def := range of {
+			res.Merge(def.CheckInDocument(doc, path, v, true))
+		}
+		if len(res.Fields) == 0 {
+			return llresult.ValidResult(path)
+		}
+		return res
+	})
+	id.Optional = optional && !keyMissing
+	id.CheckKeyMissing = keyMissing
+	return id.withSpec("all", toArgs(of)...)
+}
 
-		ust[v] = namespace
+// Not inverts the given IsDef. Like most IsDefs it requires the key to be present, whatever the wrapped
+// definition does with a missing key, so Not(KeyMissing) requires the key. Wrap it in Optional to also
+// accept a missing key.
+func Not(def IsDef) IsDef {
+	id := IsInDocument(fmt.Sprintf("not %s", def.Name), func(doc Document, path llpath.Path, v interface{}) *llresult.Results {
+		if def.CheckInDocument(doc, path, v, true).Valid {
+			return llresult.SimpleResult(
+				path,
+				false,
+				fmt.Sprintf("Value %#v should not have matched '%s'", v, def.Name),
+			)
+		}
 		return llresult.ValidResult(path)
 	})
+	return id.withSpec("not", def)
 }
 
-// ScopedIsUnique returns a new scope for uniqueness checks.
-func ScopedIsUnique() UniqScopeTracker {
-	return UniqScopeTracker{}
+// ExactlyOne takes a variable number of IsDef's and combines them with a logical XOR. The key is only
+// valid if exactly one of the definitions matches. A missing key is valid if exactly one of the definitions
+// is Optional or checks that the key is missing.
+func ExactlyOne(of ...IsDef) IsDef {
+	names := defNames(of)
+	missingMatches := 0
+	for _, def := range of {
+		if validWhenMissing(def) {
+			missingMatches++
+		}
+	}
+
+	id := IsInDocument(fmt.Sprintf("exactly one of %#v", names), func(doc Document, path llpath.Path, v interface{}) *llresult.Results {
+		var matched []string
+		var matchedRes *llresult.Results
+		failures := make([]branchFailure, 0, len(of))
+		for _, def := range of {
+			res := def.CheckInDocument(doc, path, v, true)
+			if res.Valid {
+				matched = append(matched, def.Name)
+				matchedRes = res
+			} else {
+				failures = append(failures, branchFailure{def, res})
+			}
+		}
+
+		switch len(matched) {
+		case 1:
+			return matchedRes
+		case 0:
+			return noneMatchedResults(
+				path,
+				fmt.Sprintf("Value %#v matched none of %#v", v, names),
+				failures,
+			)
+		default:
+			return llresult.SimpleResult(
+				path,
+				false,
+				fmt.Sprintf("Value %#v matched %d definitions %#v, expected exactly one", v, len(matched), matched),
+			)
+		}
+	})
+	id.Optional = missingMatches == 1
+	return id.withSpec("exactlyOne", toArgs(of)...)
 }
@@ -2,6 +2,7 @@ package isdef
 
 import (
 	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/elastic/go-lookslike/llpath"
@@ -94,3 +95,76 @@ func TestIsUnique(t *testing.T) {
 		})
 	}
 }
+
+func TestSpec(t *testing.T) {
+	re := regexp.MustCompile("^a")
+	matching := IsStringMatching(re)
+	require.NotNil(t, matching.Spec)
+	assert.Equal(t, "isStringMatching", matching.Spec.Matcher)
+	assert.Equal(t, []interface{}{re}, matching.Spec.Args)
+
+	optional := Optional(IsStringOneOf("a", "b"))
+	assert.Equal(t, &Spec{Matcher: "isStringOneOf", Args: []interface{}{"a", "b"}}, optional.Spec)
+
+	any := IsAny(IsString, IsNil)
+	assert.Equal(t, "isAny", any.Spec.Matcher)
+	assert.Equal(t, "isString", any.Spec.Args[0].(IsDef).Spec.Matcher)
+
+	custom := Is("custom", func(path llpath.Path, v interface{}) *llresult.Results {
+		return llresult.ValidResult(path)
+	})
+	assert.Nil(t, custom.Spec)
+}
+
+func TestSensitive(t *testing.T) {
+	id := Sensitive(IsStringMatching(regexp.MustCompile("^tok_")))
+	assert.Equal(t, "sensitive", id.Spec.Matcher)
+	assert.Equal(t, "isStringMatching", id.Spec.Args[0].(IsDef).Spec.Matcher)
+
+	res := assertIsDefValid(t, id, "tok_123")
+	assert.True(t, res.IsSensitive(llpath.MustParsePath("p")))
+
+	res = assertIsDefInvalid(t, id, "secret%d")
+	msg := res.Fields["p"][0].Message
+	assert.NotContains(t, msg, "secret")
+	assert.Contains(t, msg, llresult.Redacted)
+
+	// Nested values are masked wherever they are reported, but the expected value from the schema is not
+	res = Sensitive(IsEqual(map[string]interface{}{"pw": "x"})).Check(
+		llpath.MustParsePath("creds"), map[string]interface{}{"pw": "hunter2"}, true,
+	)
+	require.False(t, res.Valid)
+	res.EachResult(func(p llpath.Path, vr llresult.ValueResult) bool {
+		assert.NotContains(t, vr.Message, "hunter2", p.String())
+		return true
+	})
+	assert.Contains(t, res.Fields["creds.pw"][0].Message, "expected(string(x))")
+	assert.Contains(t, res.Fields["creds.pw"][0].Message, "actual(string([REDACTED]))")
+
+	// Only whole tokens are masked
+	res = assertIsDefInvalid(t, Sensitive(IsIntGt(10)), 1)
+	assert.Equal(t, "[REDACTED] is not greater than 10", res.Fields["p"][0].Message)
+
+	// Key presence semantics are those of the wrapped IsDef
+	assert.True(t, Sensitive(Optional(IsString)).Check(llpath.MustParsePath("p"), nil, false).Valid)
+	assert.True(t, Sensitive(KeyMissing).Check(llpath.MustParsePath("p"), nil, false).Valid)
+	assert.False(t, Sensitive(IsString).Check(llpath.MustParsePath("p"), nil, false).Valid)
+}
+
+func TestLengthBetween(t *testing.T) {
+	for _, tc := range []struct {
+		length, min, max int
+		expected         string
+	}{
+		{2, 1, 3, ""},
+		{5, 1, -1, ""},
+		{4, 1, 3, "between 1 and 3"},
+		{2, 3, 3, "3"},
+		{0, 1, -1, "at least 1"},
+		{4, 0, 3, "at most 3"},
+	} {
+		expected, ok := lengthBetween(tc.length, tc.min, tc.max)
+		assert.Equal(t, tc.expected == "", ok)
+		assert.Equal(t, tc.expected, expected)
+	}
+}
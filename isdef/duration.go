@@ -18,4 +18,4 @@ var IsDuration = Is("is a duration", func(path llpath.Path, v interface{}) *llre
 		false,
 		fmt.Sprintf("Expected a time.duration, got '%v' which is a %T", v, v),
 	)
-})
+}).withSpec("isDuration")
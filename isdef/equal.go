@@ -0,0 +1,284 @@
+package isdef
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+)
+
+// EqualRegistry holds the functions IsEqual uses to check equality for particular types. Handlers are
+// functions of the form fn(v someType) IsDef. When someType is an interface the handler is used for every
+// type implementing it that has no handler of its own.
+// An EqualRegistry is safe for concurrent use.
+type EqualRegistry struct {
+	mu       sync.RWMutex
+	handlers map[reflect.Type]reflect.Value
+	// ifaces holds the interface types with handlers, in the order they were registered.
+	ifaces []reflect.Type
+}
+
+// DefaultEqualRegistry is used by IsEqual, RegisterEqual and compiled schemas that are not given a registry
+// of their own.
+var DefaultEqualRegistry = NewEqualRegistry()
+
+// NewEqualRegistry returns an EqualRegistry containing the built-in handlers, currently time.Time.
+func NewEqualRegistry() *EqualRegistry {
+	r := &EqualRegistry{handlers: map[reflect.Type]reflect.Value{}}
+	r.MustRegister(IsEqualToTime)
+	return r
+}
+
+// Clone returns a copy of the registry that can be changed without affecting the original, useful for
+// starting from the handlers in DefaultEqualRegistry.
+func (r *EqualRegistry) Clone() *EqualRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c := &EqualRegistry{
+		handlers: make(map[reflect.Type]reflect.Value, len(r.handlers)),
+		ifaces:   append([]reflect.Type(nil), r.ifaces...),
+	}
+	for t, fn := range r.handlers {
+		c.handlers[t] = fn
+	}
+	return c
+}
+
+// Register adds the given handler, returning an InvalidEqualFnError if it is not a valid handler or its type
+// already has one.
+func (r *EqualRegistry) Register(fn interface{}) error {
+	return r.add(fn, false)
+}
+
+// MustRegister is the panic-ing equivalent of Register.
+func (r *EqualRegistry) MustRegister(fn interface{}) {
+	if err := r.Register(fn); err != nil {
+		panic(fmt.Sprintf("Could not register fn as equal! %v", err))
+	}
+}
+
+// Set adds the given handler, replacing any handler already registered for its type.
+func (r *EqualRegistry) Set(fn interface{}) error {
+	return r.add(fn, true)
+}
+
+// Remove removes the handler registered for the given type, returning false if there was none.
+// Values of that type fall back to other matching interface handlers or reflect.DeepEqual.
+func (r *EqualRegistry) Remove(t reflect.Type) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.handlers[t]; !ok {
+		return false
+	}
+	delete(r.handlers, t)
+	if t.Kind() == reflect.Interface {
+		for i, it := range r.ifaces {
+			if it == t {
+				r.ifaces = append(r.ifaces[:i:i], r.ifaces[i+1:]...)
+				break
+			}
+		}
+	}
+	return true
+}
+
+// Lookup returns the handler for values of the given type. A handler registered for the type itself wins,
+// otherwise the first registered interface handler the type implements is used.
+func (r *EqualRegistry) Lookup(t reflect.Type) (func(to interface{}) IsDef, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fnV, ok := r.handlers[t]
+	if !ok {
+		for _, it := range r.ifaces {
+			if t.Implements(it) {
+				fnV, ok = r.handlers[it], true
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+
+	return func(to interface{}) IsDef {
+		// We know this is an isdef due to the Register check previously
+		return fnV.Call([]reflect.Value{reflect.ValueOf(to)})[0].Interface().(IsDef)
+	}, true
+}
+
+// IsEqual is like the package level IsEqual, but uses the handlers in this registry.
+func (r *EqualRegistry) IsEqual(to interface{}) IsDef {
+	if to == nil {
+		return IsNil.withSpec("isEqual", nil)
+	}
+
+	isDefFactory, ok := r.Lookup(reflect.TypeOf(to))
+
+	// If there are no handlers declared explicitly for this type we use the type's own notion of equality,
+	// falling back to a deep equality check
+	if !ok {
+		if def, ok := methodEqual(to); ok {
+			return def.withSpec("isEqual", to)
+		}
+		return IsDeepEqual(to).withSpec("isEqual", to)
+	}
+
+	checker := isDefFactory(to).Checker
+
+	return Is("equals", func(path llpath.Path, v interface{}) *llresult.Results {
+		return checker(path, v)
+	}).withSpec("isEqual", to)
+}
+
+func (r *EqualRegistry) add(fn interface{}, replace bool) error {
+	fnV := reflect.ValueOf(fn)
+	if !fnV.IsValid() {
+		return InvalidEqualFnError{"Provided value is not a function"}
+	}
+	fnT := fnV.Type()
+
+	if fnT.Kind() != reflect.Func {
+		return InvalidEqualFnError{"Provided value is not a function"}
+	}
+	if fnT.NumIn() != 1 {
+		return InvalidEqualFnError{"Equal FN should take one argument"}
+	}
+	if fnT.NumOut() != 1 {
+		return InvalidEqualFnError{"Equal FN should return one value"}
+	}
+	if fnT.Out(0) != reflect.TypeOf(IsDef{}) {
+		return InvalidEqualFnError{"Equal FN should return an IsDef"}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inT := fnT.In(0)
+	_, exists := r.handlers[inT]
+	if exists && !replace {
+		return InvalidEqualFnError{fmt.Sprintf("Duplicate Equal FN for type %v encountered!", inT)}
+	}
+
+	r.handlers[inT] = fnV
+	if !exists && inT.Kind() == reflect.Interface {
+		r.ifaces = append(r.ifaces, inT)
+	}
+
+	return nil
+}
+
+// equalMethods are the methods methodEqual looks for, in order of preference, along with how to interpret
+// their result.
+var equalMethods = []struct {
+	name  string
+	equal func(out reflect.Value) bool
+}{
+	{"Equal", func(out reflect.Value) bool { return out.Bool() }},
+	{"Compare", func(out reflect.Value) bool { return out.Int() == 0 }},
+	{"Cmp", func(out reflect.Value) bool { return out.Int() == 0 }},
+}
+
+// methodEqual returns an IsDef comparing values using an Equal(T) bool, Compare(T) int or Cmp(T) int method on
+// to's type, where T is the type of to. Methods with a pointer receiver are used for non-pointer values as well.
+func methodEqual(to interface{}) (IsDef, bool) {
+	toV := reflect.ValueOf(to)
+	if toV.Kind() == reflect.Ptr && toV.IsNil() {
+		return IsDef{}, false
+	}
+	if toV.Kind() != reflect.Ptr {
+		// Make the value addressable so methods with pointer receivers are in its method set
+		addressable := reflect.New(toV.Type()).Elem()
+		addressable.Set(toV)
+		toV = addressable
+	}
+
+	for _, em := range equalMethods {
+		method, argT := equalMethod(toV, em.name)
+		if !method.IsValid() {
+			continue
+		}
+
+		em := em
+		return Is("equals", func(path llpath.Path, v interface{}) *llresult.Results {
+			arg, ok := methodArg(v, argT)
+			if !ok {
+				return llresult.SimpleResult(path, false, "objects not equal: actual(%T(%v)) is not of type %v", v, v, argT)
+			}
+			if em.equal(method.Call([]reflect.Value{arg})[0]) {
+				return llresult.ValidResult(path)
+			}
+			return llresult.SimpleResult(
+				path,
+				false,
+				"objects not equal according to %s: actual(%T(%v)) != expected(%T(%v))", em.name, v, v, to, to,
+			)
+		}), true
+	}
+	return IsDef{}, false
+}
+
+// equalMethod finds the named method on v, or on its address, taking a single argument of v's type, ignoring
+// pointers.
+func equalMethod(v reflect.Value, name string) (method reflect.Value, argT reflect.Type) {
+	candidates := []reflect.Value{v}
+	if v.CanAddr() {
+		candidates = append(candidates, v.Addr())
+	}
+
+	for _, c := range candidates {
+		m := c.MethodByName(name)
+		if !m.IsValid() {
+			continue
+		}
+		mT := m.Type()
+		if mT.NumIn() != 1 || mT.NumOut() != 1 || mT.IsVariadic() {
+			continue
+		}
+		in := mT.In(0)
+		if !sameBaseType(in, v.Type()) {
+			continue
+		}
+		outKind := mT.Out(0).Kind()
+		if (name == "Equal" && outKind != reflect.Bool) || (name != "Equal" && outKind != reflect.Int) {
+			continue
+		}
+		return m, in
+	}
+	return reflect.Value{}, nil
+}
+
+// methodArg converts the actual value to the argument type of an equality method, taking the address of a copy
+// if the method expects a pointer. Nil pointers are never passed on, since most methods would panic.
+func methodArg(v interface{}, argT reflect.Type) (reflect.Value, bool) {
+	vV := reflect.ValueOf(v)
+	if !vV.IsValid() {
+		return reflect.Value{}, false
+	}
+	if vV.Kind() == reflect.Ptr && vV.IsNil() {
+		return reflect.Value{}, false
+	}
+
+	switch {
+	case vV.Type() == argT:
+		return vV, true
+	case argT.Kind() == reflect.Ptr && vV.Type() == argT.Elem():
+		ptr := reflect.New(argT.Elem())
+		ptr.Elem().Set(vV)
+		return ptr, true
+	case vV.Kind() == reflect.Ptr && vV.Type().Elem() == argT:
+		return vV.Elem(), true
+	}
+	return reflect.Value{}, false
+}
+
+// sameBaseType reports whether a and b are the same type, or one is a pointer to the other.
+func sameBaseType(a, b reflect.Type) bool {
+	return a == b ||
+		(a.Kind() == reflect.Ptr && a.Elem() == b) ||
+		(b.Kind() == reflect.Ptr && b.Elem() == a)
+}
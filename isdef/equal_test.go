@@ -0,0 +1,132 @@
+package isdef
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type equalTestCode string
+
+func (c equalTestCode) String() string { return string(c) }
+
+func TestEqualRegistry(t *testing.T) {
+	r := NewEqualRegistry()
+
+	// Built-in handlers are present
+	now := time.Now()
+	assertIsDefValid(t, r.IsEqual(now), now.In(time.UTC))
+
+	err := r.Register(func(to time.Time) IsDef { return IsAny() })
+	assert.IsType(t, InvalidEqualFnError{}, err)
+	assert.Contains(t, err.Error(), "Duplicate Equal FN for type time.Time")
+
+	// Set overrides the existing handler
+	require.NoError(t, r.Set(func(to time.Time) IsDef {
+		return Is("same second", func(path llpath.Path, v interface{}) *llresult.Results {
+			return llresult.SimpleResult(path, v.(time.Time).Truncate(time.Second).Equal(to.Truncate(time.Second)), "different second")
+		})
+	}))
+	sec := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	assertIsDefValid(t, r.IsEqual(sec), sec.Add(time.Millisecond))
+
+	// Removing the handler falls back to reflect.DeepEqual
+	assert.True(t, r.Remove(reflect.TypeOf(time.Time{})))
+	assert.False(t, r.Remove(reflect.TypeOf(time.Time{})))
+	assertIsDefInvalid(t, r.IsEqual(sec), sec.Add(time.Millisecond))
+
+	// None of this affects the default registry
+	assertIsDefValid(t, IsEqual(now), now.In(time.UTC))
+	assertIsDefInvalid(t, IsEqual(sec), sec.Add(time.Millisecond))
+}
+
+func TestEqualRegistryInterfaces(t *testing.T) {
+	r := NewEqualRegistry()
+	require.NoError(t, r.Register(func(to fmt.Stringer) IsDef {
+		return Is("same string", func(path llpath.Path, v interface{}) *llresult.Results {
+			s, ok := v.(fmt.Stringer)
+			return llresult.SimpleResult(path, ok && strings.EqualFold(s.String(), to.String()), "different string")
+		})
+	}))
+
+	_, ok := r.Lookup(reflect.TypeOf(equalTestCode("")))
+	assert.True(t, ok)
+	_, ok = r.Lookup(reflect.TypeOf(""))
+	assert.False(t, ok)
+
+	assertIsDefValid(t, r.IsEqual(equalTestCode("ABC")), equalTestCode("abc"))
+	assertIsDefInvalid(t, r.IsEqual(equalTestCode("ABC")), equalTestCode("abd"))
+
+	// A concrete handler wins over an interface handler
+	require.NoError(t, r.Register(func(to equalTestCode) IsDef { return IsDeepEqual(to) }))
+	assertIsDefInvalid(t, r.IsEqual(equalTestCode("ABC")), equalTestCode("abc"))
+
+	c := r.Clone()
+	c.Remove(reflect.TypeOf(equalTestCode("")))
+	assertIsDefValid(t, c.IsEqual(equalTestCode("ABC")), equalTestCode("abc"))
+	assertIsDefInvalid(t, r.IsEqual(equalTestCode("ABC")), equalTestCode("abc"))
+}
+
+func TestEqualRegistryConcurrency(t *testing.T) {
+	r := NewEqualRegistry()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = r.Set(func(to int) IsDef { return IsDeepEqual(to) })
+			r.Remove(reflect.TypeOf(0))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		assertIsDefValid(t, r.IsEqual(i), i)
+	}
+	<-done
+}
+
+type equalTestVersion struct {
+	major, minor int
+	label        string
+}
+
+// Equal ignores labels
+func (v equalTestVersion) Equal(o equalTestVersion) bool {
+	return v.major == o.major && v.minor == o.minor
+}
+
+func TestIsEqualMethods(t *testing.T) {
+	v := equalTestVersion{1, 2, "stable"}
+	assertIsDefValid(t, IsEqual(v), equalTestVersion{1, 2, "beta"})
+	assertIsDefValid(t, IsEqual(v), &equalTestVersion{1, 2, "beta"})
+	assertIsDefValid(t, IsEqual(&v), equalTestVersion{1, 2, "beta"})
+	assertIsDefInvalid(t, IsEqual(v), equalTestVersion{1, 3, "stable"})
+	assertIsDefInvalid(t, IsEqual(v), (*equalTestVersion)(nil))
+	res := assertIsDefInvalid(t, IsEqual(v), "1.2")
+	assert.Contains(t, res.Errors()[0].Error(), "is not of type isdef.equalTestVersion")
+
+	// big.Int has a Cmp method with a pointer receiver, and zeros are not always deeply equal
+	zero := big.NewInt(5)
+	zero.Sub(zero, zero)
+	assertIsDefInvalid(t, IsDeepEqual(new(big.Int)), zero)
+	assertIsDefValid(t, IsEqual(new(big.Int)), zero)
+	assertIsDefValid(t, IsEqual(*big.NewInt(7)), big.NewInt(7))
+	assertIsDefInvalid(t, IsEqual(big.NewInt(7)), big.NewInt(8))
+
+	// netip.Addr has a Compare method
+	addr := netip.MustParseAddr("10.0.0.1")
+	assertIsDefValid(t, IsEqual(addr), netip.AddrFrom4([4]byte{10, 0, 0, 1}))
+	assertIsDefInvalid(t, IsEqual(addr), netip.MustParseAddr("10.0.0.2"))
+
+	// Registered handlers take precedence over methods
+	r := NewEqualRegistry()
+	r.MustRegister(func(to equalTestVersion) IsDef { return IsDeepEqual(to) })
+	assertIsDefInvalid(t, r.IsEqual(v), equalTestVersion{1, 2, "beta"})
+}
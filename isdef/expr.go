@@ -0,0 +1,236 @@
+package isdef
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ExprError describes why a matcher expression could not be parsed or built.
+type ExprError struct {
+	Expr string
+	// Pos is the byte offset in Expr at which the problem was found.
+	Pos int
+	Msg string
+}
+
+func (e ExprError) Error() string {
+	return fmt.Sprintf("invalid matcher expression '%s' at position %d: %s", e.Expr, e.Pos, e.Msg)
+}
+
+// Parse parses a matcher expression into an IsDef, e.g. `isIntGt(5)` or `isAny(isNil, isStringMatching("^a"))`.
+// An expression is the name of a registered Matcher, optionally followed by a parenthesized list of arguments.
+// Arguments are numbers, double-quoted or backquoted strings, true, false, nil or nested expressions.
+// Errors are returned as ExprError.
+func (r *Registry) Parse(expr string) (IsDef, error) {
+	p := &exprParser{registry: r, expr: expr}
+	p.skipSpace()
+	def, err := p.matcher()
+	if err != nil {
+		return IsDef{}, err
+	}
+	p.skipSpace()
+	if p.pos < len(expr) {
+		return IsDef{}, p.errorf("unexpected '%c' after the expression", expr[p.pos])
+	}
+	return def, nil
+}
+
+// MustParse is the panic-ing equivalent of Parse.
+func (r *Registry) MustParse(expr string) IsDef {
+	def, err := r.Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return def
+}
+
+// ParseMatcher parses a matcher expression using DefaultRegistry, see Registry.Parse.
+func ParseMatcher(expr string) (IsDef, error) {
+	return DefaultRegistry.Parse(expr)
+}
+
+// MustParseMatcher is the panic-ing equivalent of ParseMatcher.
+func MustParseMatcher(expr string) IsDef {
+	return DefaultRegistry.MustParse(expr)
+}
+
+type exprParser struct {
+	registry *Registry
+	expr     string
+	pos      int
+}
+
+func (p *exprParser) errorf(msg string, args ...interface{}) ExprError {
+	return ExprError{Expr: p.expr, Pos: p.pos, Msg: fmt.Sprintf(msg, args...)}
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.expr) && unicode.IsSpace(rune(p.expr[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *exprParser) peek() byte {
+	if p.pos < len(p.expr) {
+		return p.expr[p.pos]
+	}
+	return 0
+}
+
+func (p *exprParser) identifier() string {
+	start := p.pos
+	for p.pos < len(p.expr) && isIdentByte(p.expr[p.pos], p.pos == start) {
+		p.pos++
+	}
+	return p.expr[start:p.pos]
+}
+
+func (p *exprParser) matcher() (IsDef, error) {
+	start := p.pos
+	name := p.identifier()
+	if name == "" {
+		return IsDef{}, p.errorf("expected a matcher name")
+	}
+
+	var args []interface{}
+	p.skipSpace()
+	if p.peek() == '(' {
+		p.pos++
+		p.skipSpace()
+		for p.peek() != ')' {
+			arg, err := p.arg()
+			if err != nil {
+				return IsDef{}, err
+			}
+			args = append(args, arg)
+
+			p.skipSpace()
+			switch p.peek() {
+			case ',':
+				p.pos++
+				p.skipSpace()
+			case ')':
+			default:
+				return IsDef{}, p.errorf("expected ',' or ')'")
+			}
+		}
+		p.pos++
+	}
+
+	def, err := p.registry.Build(name, args...)
+	if err != nil {
+		return IsDef{}, ExprError{Expr: p.expr, Pos: start, Msg: err.Error()}
+	}
+	return def, nil
+}
+
+func (p *exprParser) arg() (interface{}, error) {
+	c := p.peek()
+	switch {
+	case c == '"' || c == '`':
+		return p.str()
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		return p.number()
+	case isIdentByte(c, true):
+		start := p.pos
+		switch p.identifier() {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "nil":
+			return nil, nil
+		}
+		p.pos = start
+		return p.matcher()
+	case c == 0:
+		return nil, p.errorf("unexpected end of expression")
+	}
+	return nil, p.errorf("unexpected '%c'", c)
+}
+
+func (p *exprParser) str() (interface{}, error) {
+	quote := p.expr[p.pos]
+	start := p.pos
+	for p.pos++; p.pos < len(p.expr); p.pos++ {
+		switch p.expr[p.pos] {
+		case '\\':
+			if quote == '"' {
+				p.pos++
+			}
+		case quote:
+			p.pos++
+			s, err := strconv.Unquote(p.expr[start:p.pos])
+			if err != nil {
+				return nil, ExprError{Expr: p.expr, Pos: start, Msg: fmt.Sprintf("invalid string: %s", err)}
+			}
+			return s, nil
+		}
+	}
+	return nil, ExprError{Expr: p.expr, Pos: start, Msg: "unterminated string"}
+}
+
+func (p *exprParser) number() (interface{}, error) {
+	start := p.pos
+	for p.pos < len(p.expr) && strings.IndexByte("+-.0123456789eExXabcdefABCDEF_", p.expr[p.pos]) >= 0 {
+		p.pos++
+	}
+	lit := p.expr[start:p.pos]
+
+	if i, err := strconv.ParseInt(lit, 0, 64); err == nil {
+		return int(i), nil
+	}
+	if f, err := strconv.ParseFloat(lit, 64); err == nil {
+		return f, nil
+	}
+	return nil, ExprError{Expr: p.expr, Pos: start, Msg: fmt.Sprintf("invalid number '%s'", lit)}
+}
+
+func isIdentByte(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
+
+func isIdentifier(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isIdentByte(s[i], i == 0) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// String formats the Spec as a matcher expression that Parse turns back into an equivalent IsDef, as long as
+// every argument can be written in an expression.
+func (s Spec) String() string {
+	if len(s.Args) == 0 {
+		return s.Matcher
+	}
+
+	args := make([]string, len(s.Args))
+	for i, arg := range s.Args {
+		switch a := arg.(type) {
+		case nil:
+			args[i] = "nil"
+		case string:
+			args[i] = strconv.Quote(a)
+		case JSONType:
+			args[i] = strconv.Quote(string(a))
+		case IPConstraint:
+			args[i] = strconv.Quote(a.String())
+		case *regexp.Regexp:
+			args[i] = strconv.Quote(a.String())
+		case IsDef:
+			if a.Spec != nil {
+				args[i] = a.Spec.String()
+			} else {
+				args[i] = a.Name
+			}
+		default:
+			args[i] = fmt.Sprintf("%v", a)
+		}
+	}
+	return fmt.Sprintf("%s(%s)", s.Matcher, strings.Join(args, ", "))
+}
@@ -0,0 +1,119 @@
+package isdef
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMatcher(t *testing.T) {
+	id, err := ParseMatcher("isIntGt(5)")
+	require.NoError(t, err)
+	assertIsDefValid(t, id, 6)
+	assertIsDefInvalid(t, id, 5)
+
+	id, err = ParseMatcher(` isAny( isNil, isStringMatching(` + "`^a\\d`" + `), isGte(-1.5) ) `)
+	require.NoError(t, err)
+	assertIsDefValid(t, id, nil)
+	assertIsDefValid(t, id, "a1")
+	assertIsDefValid(t, id, -1)
+	assertIsDefInvalid(t, id, "b1")
+	assertIsDefInvalid(t, id, -2)
+
+	id, err = ParseMatcher(`isStringOneOf("up", "down\n")`)
+	require.NoError(t, err)
+	assertIsDefValid(t, id, "down\n")
+
+	id, err = ParseMatcher(`isMapLengthBetween(0x1, 2)`)
+	require.NoError(t, err)
+	assertIsDefValid(t, id, map[string]interface{}{"a": 1})
+
+	id, err = ParseMatcher(`isEqual(true)`)
+	require.NoError(t, err)
+	assertIsDefValid(t, id, true)
+}
+
+func TestParseMatcherErrors(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		pos  int
+		msg  string
+	}{
+		{"", 0, "expected a matcher name"},
+		{"isIntGt(5", 9, "expected ',' or ')'"},
+		{"isIntGt(5))", 10, "unexpected ')' after the expression"},
+		{"isAny(isNil, isNope)", 13, "unknown matcher 'isNope'"},
+		{`isStringContaining("abc)`, 19, "unterminated string"},
+		{"isIntGt(1.5)", 0, "argument 1 of matcher 'isIntGt': expected int"},
+		{"isIntGt(1.2.3)", 8, "invalid number '1.2.3'"},
+		{"isIntGt(,)", 8, "unexpected ','"},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			_, err := ParseMatcher(tc.expr)
+			require.Error(t, err)
+			exprErr, ok := err.(ExprError)
+			require.True(t, ok, "%v", err)
+			assert.Equal(t, tc.pos, exprErr.Pos)
+			assert.Contains(t, exprErr.Msg, tc.msg)
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	isMultipleOf := Matcher{
+		Name:   "isMultipleOf",
+		Params: []ParamType{ParamInt},
+		Build: func(args []interface{}) (IsDef, error) {
+			n := args[0].(int)
+			return Is("multiple of", func(path llpath.Path, v interface{}) *llresult.Results {
+				return llresult.SimpleResult(path, v.(int)%n == 0, "Value %v is not a multiple of %d", v, n)
+			}), nil
+		},
+	}
+	require.NoError(t, r.Register(isMultipleOf))
+	assert.EqualError(t, r.Register(isMultipleOf), "a matcher named 'isMultipleOf' is already registered")
+	assert.Error(t, r.Register(Matcher{Name: "not valid", Build: isMultipleOf.Build}))
+
+	id, err := r.Parse("all(isMultipleOf(3), isIntGt(10))")
+	require.NoError(t, err)
+	assertIsDefValid(t, id, 12)
+	assertIsDefInvalid(t, id, 9)
+	assertIsDefInvalid(t, id, 13)
+
+	// Matchers without a Spec of their own are described by how they were built
+	id, err = r.Build("isMultipleOf", 3.0)
+	require.NoError(t, err)
+	assert.Equal(t, &Spec{Matcher: "isMultipleOf", Args: []interface{}{3}}, id.Spec)
+
+	// Registries are independent of each other
+	_, ok := DefaultRegistry.Lookup("isMultipleOf")
+	assert.False(t, ok)
+	assert.Contains(t, r.Names(), "isMultipleOf")
+	assert.Contains(t, r.Names(), "isIntGt")
+}
+
+func TestSpecString(t *testing.T) {
+	for _, def := range []IsDef{
+		IsString,
+		IsIntGt(5),
+		IsStringMatching(regexp.MustCompile(`^"a"\d$`)),
+		IsAny(IsNil, IsStringOneOf("a", "b")),
+		IsStringLengthBetween(1, -1),
+		IsIP(IPv4, Global),
+		IsJSONType(JSONString, JSONNull),
+		IsGt(1.5),
+	} {
+		expr := def.Spec.String()
+		parsed, err := ParseMatcher(expr)
+		require.NoError(t, err, expr)
+		assert.Equal(t, expr, parsed.Spec.String())
+	}
+
+	assert.Equal(t, `isAny(isNil, isStringOneOf("a", "b"))`, IsAny(IsNil, IsStringOneOf("a", "b")).Spec.String())
+}
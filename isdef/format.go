@@ -0,0 +1,81 @@
+package isdef
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/mail"
+	"regexp"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+)
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// IsUUID checks that the given value is a string in the canonical 8-4-4-4-12 UUID format.
+// The version and variant bits are not checked.
+var IsUUID = Is("is a UUID", func(path llpath.Path, v interface{}) *llresult.Results {
+	strV, errorResults := isStrCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	if !uuidRegexp.MatchString(strV) {
+		return llresult.SimpleResult(path, false, "'%s' is not a valid UUID", strV)
+	}
+	return llresult.ValidResult(path)
+}).withSpec("isUUID")
+
+// IsEmail checks that the given value is a string containing a bare RFC 5322 address, such as
+// "user@example.net". Addresses with display names, like "User <user@example.net>", are rejected.
+var IsEmail = Is("is an email address", func(path llpath.Path, v interface{}) *llresult.Results {
+	strV, errorResults := isStrCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	addr, err := mail.ParseAddress(strV)
+	if err != nil {
+		return llresult.SimpleResult(path, false, "'%s' is not a valid email address: %s", strV, err)
+	}
+	if addr.Name != "" || addr.Address != strV {
+		return llresult.SimpleResult(path, false, "'%s' is not a bare email address", strV)
+	}
+	return llresult.ValidResult(path)
+}).withSpec("isEmail")
+
+// IsHex checks that the given value is a string of hex encoded bytes. If length is positive
+// the string must instead contain exactly that many hex characters, which may be an odd number,
+// e.g. IsHex(64) for a SHA-256 digest or IsHex(7) for a short git commit ID.
+func IsHex(length int) IsDef {
+	return Is("is a hex string", func(path llpath.Path, v interface{}) *llresult.Results {
+		strV, errorResults := isStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if length <= 0 {
+			if _, err := hex.DecodeString(strV); err != nil {
+				return llresult.SimpleResult(path, false, "'%s' is not a valid hex string: %s", strV, err)
+			}
+			return llresult.ValidResult(path)
+		}
+		for _, r := range strV {
+			if !isHexDigit(r) {
+				return llresult.SimpleResult(path, false, "'%s' is not a valid hex string: invalid character %q", strV, r)
+			}
+		}
+		if len(strV) != length {
+			return llresult.SimpleResult(
+				path,
+				false,
+				fmt.Sprintf("Hex string '%s' has length %d, expected length %d", strV, len(strV), length),
+			)
+		}
+		return llresult.ValidResult(path)
+	}).withSpec("isHex", length)
+}
+
+func isHexDigit(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
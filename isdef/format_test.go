@@ -0,0 +1,35 @@
+package isdef
+
+import "testing"
+
+func TestIsUUID(t *testing.T) {
+	assertIsDefValid(t, IsUUID, "123e4567-e89b-12d3-a456-426614174000")
+	assertIsDefValid(t, IsUUID, "123E4567-E89B-12D3-A456-426614174000")
+	assertIsDefInvalid(t, IsUUID, "123e4567e89b12d3a456426614174000")
+	assertIsDefInvalid(t, IsUUID, "123e4567-e89b-12d3-a456-42661417400g")
+	assertIsDefInvalid(t, IsUUID, 123)
+}
+
+func TestIsEmail(t *testing.T) {
+	assertIsDefValid(t, IsEmail, "user@example.net")
+	assertIsDefValid(t, IsEmail, "first.last+tag@example.net")
+	assertIsDefInvalid(t, IsEmail, "User <user@example.net>")
+	assertIsDefInvalid(t, IsEmail, "user.example.net")
+	assertIsDefInvalid(t, IsEmail, 123)
+}
+
+func TestIsHex(t *testing.T) {
+	sha1 := IsHex(40)
+
+	assertIsDefValid(t, IsHex(0), "deadBEEF")
+	assertIsDefValid(t, sha1, "da39a3ee5e6b4b0d3255bfef95601890afd80709")
+	assertIsDefInvalid(t, sha1, "d41d8cd98f00b204e9800998ecf8427e")
+	assertIsDefInvalid(t, IsHex(0), "abc")
+	assertIsDefInvalid(t, IsHex(0), "zz")
+	assertIsDefInvalid(t, IsHex(0), 123)
+
+	// Odd lengths are possible when a length is given
+	assertIsDefValid(t, IsHex(7), "a1b2c3d")
+	assertIsDefInvalid(t, IsHex(7), "a1b2c3")
+	assertIsDefInvalid(t, IsHex(7), "a1b2c3g")
+}
@@ -29,5 +29,5 @@ func intGtChecker(than int) ValueValidator {
 
 // IsIntGt tests that a value is an int greater than.
 func IsIntGt(than int) IsDef {
-	return Is("greater than", intGtChecker(than))
+	return Is("greater than", intGtChecker(than)).withSpec("isIntGt", than)
 }
@@ -0,0 +1,178 @@
+package isdef
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+	"github.com/elastic/go-lookslike/validator"
+)
+
+// isMapCheck is a helper for IsDefs that must assert that the value is a map first. The map's entries
+// are returned keyed by the string form of their keys.
+func isMapCheck(path llpath.Path, v interface{}) (entries map[string]interface{}, errorResults *llresult.Results) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map {
+		return nil, llresult.SimpleResult(
+			path,
+			false,
+			fmt.Sprintf("Expected a map, got '%v' which is a %T", v, v),
+		)
+	}
+
+	entries = make(map[string]interface{}, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		var key string
+		if iter.Key().Kind() == reflect.String {
+			key = iter.Key().String()
+		} else {
+			key = fmt.Sprint(iter.Key().Interface())
+		}
+
+		var value interface{}
+		if mv := iter.Value(); !(mv.Kind() == reflect.Interface && mv.IsNil()) {
+			value = mv.Interface()
+		}
+		entries[key] = value
+	}
+	return entries, nil
+}
+
+// sortedKeys returns the keys of the given map in order, so results are recorded deterministically.
+func sortedKeys(entries map[string]interface{}) []string {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// recordValidKeys records each key of the map at the given path without a result of its own as valid, so
+// that Strict does not report keys the check accepted.
+func recordValidKeys(path llpath.Path, entries map[string]interface{}, res *llresult.Results) {
+	for _, k := range sortedKeys(entries) {
+		if _, ok := res.Fields[path.ExtendMap(k).String()]; !ok {
+			res.Record(path.ExtendMap(k), llresult.ValidVR)
+		}
+	}
+}
+
+// IsMapWithKeys checks that the map at the given path has all of the given keys. Other keys are allowed.
+// A result is recorded for each key, so missing keys are reported at their own path and other keys are
+// recorded as valid.
+func IsMapWithKeys(keys ...string) IsDef {
+	return Is("map with keys", func(path llpath.Path, v interface{}) *llresult.Results {
+		entries, errorResults := isMapCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		res := llresult.ValidResult(path)
+		for _, k := range keys {
+			if _, ok := entries[k]; ok {
+				res.Record(path.ExtendMap(k), llresult.ValidVR)
+			} else {
+				res.Record(path.ExtendMap(k), llresult.KeyMissingVR)
+			}
+		}
+		recordValidKeys(path, entries, res)
+		return res
+	}).withSpec("isMapWithKeys", toArgs(keys)...)
+}
+
+// IsMapWithExactKeys checks that the map at the given path has exactly the given keys. Keys that are not
+// expected are reported at their own path, in the same way as Strict reports unexpected fields.
+func IsMapWithExactKeys(keys ...string) IsDef {
+	return Is("map with exact keys", func(path llpath.Path, v interface{}) *llresult.Results {
+		entries, errorResults := isMapCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		expected := make(map[string]struct{}, len(keys))
+		res := llresult.ValidResult(path)
+		for _, k := range keys {
+			expected[k] = struct{}{}
+			if _, ok := entries[k]; ok {
+				res.Record(path.ExtendMap(k), llresult.ValidVR)
+			} else {
+				res.Record(path.ExtendMap(k), llresult.KeyMissingVR)
+			}
+		}
+		for _, k := range sortedKeys(entries) {
+			if _, ok := expected[k]; !ok {
+				res.Record(path.ExtendMap(k), llresult.StrictFailureVR)
+			}
+		}
+		return res
+	}).withSpec("isMapWithExactKeys", toArgs(keys)...)
+}
+
+// IsMapKeysMatching checks that every key of the map at the given path matches the given regexp.
+// A result is recorded for each key.
+func IsMapKeysMatching(regexp *regexp.Regexp) IsDef {
+	return Is("map keys matching regexp", func(path llpath.Path, v interface{}) *llresult.Results {
+		entries, errorResults := isMapCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		res := llresult.ValidResult(path)
+		for _, k := range sortedKeys(entries) {
+			if regexp.MatchString(k) {
+				res.Record(path.ExtendMap(k), llresult.ValidVR)
+			} else {
+				res.Record(path.ExtendMap(k), llresult.ValueResult{
+					Valid:   false,
+					Message: fmt.Sprintf("Key '%s' did not match regexp %s", k, regexp.String()),
+				})
+			}
+		}
+		return res
+	}).withSpec("isMapKeysMatching", regexp)
+}
+
+// IsMapLengthBetween checks that the map at the given path has between min and max entries inclusive.
+// A negative max means there is no upper bound. When the length is valid each key is recorded as valid.
+func IsMapLengthBetween(min, max int) IsDef {
+	return Is("map with length between", func(path llpath.Path, v interface{}) *llresult.Results {
+		entries, errorResults := isMapCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		length := len(entries)
+		if expected, ok := lengthBetween(length, min, max); !ok {
+			return llresult.SimpleResult(
+				path,
+				false,
+				fmt.Sprintf("Map has %d entries, expected %s", length, expected),
+			)
+		}
+		res := llresult.ValidResult(path)
+		recordValidKeys(path, entries, res)
+		return res
+	}).withSpec("isMapLengthBetween", min, max)
+}
+
+// IsMapOf validates every value of the map at the given path with the given validator.Validator.
+// Results are recorded under the path of each key, so the map is fully validated when used with Strict.
+func IsMapOf(validator validator.Validator) IsDef {
+	return Is("map of", func(path llpath.Path, v interface{}) *llresult.Results {
+		entries, errorResults := isMapCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		res := llresult.ValidResult(path)
+		for _, k := range sortedKeys(entries) {
+			res.MergeUnderPrefix(path.ExtendMap(k), validator(entries[k]))
+		}
+		return res
+	}).withSpec("isMapOf", validator)
+}
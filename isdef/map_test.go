@@ -0,0 +1,73 @@
+package isdef
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/elastic/go-lookslike/llresult"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsMapWithKeys(t *testing.T) {
+	id := IsMapWithKeys("a", "b")
+
+	res := assertIsDefValid(t, id, map[string]interface{}{"a": 1, "b": nil, "c": 3})
+	assert.Contains(t, res.Fields, "p.a")
+	assert.Contains(t, res.Fields, "p.b")
+	assert.Equal(t, []llresult.ValueResult{llresult.ValidVR}, res.Fields["p.c"])
+
+	res = assertIsDefInvalid(t, id, map[string]int{"a": 1})
+	assert.Equal(t, []llresult.ValueResult{llresult.KeyMissingVR}, res.Fields["p.b"])
+
+	assertIsDefInvalid(t, id, []string{"a", "b"})
+}
+
+func TestIsMapWithExactKeys(t *testing.T) {
+	id := IsMapWithExactKeys("a", "b")
+
+	assertIsDefValid(t, id, map[string]interface{}{"a": 1, "b": 2})
+
+	res := assertIsDefInvalid(t, id, map[string]interface{}{"a": 1, "b": 2, "c": 3})
+	assert.Equal(t, []llresult.ValueResult{llresult.StrictFailureVR}, res.Fields["p.c"])
+
+	res = assertIsDefInvalid(t, id, map[string]interface{}{"a": 1})
+	assert.Equal(t, []llresult.ValueResult{llresult.KeyMissingVR}, res.Fields["p.b"])
+}
+
+func TestIsMapKeysMatching(t *testing.T) {
+	id := IsMapKeysMatching(regexp.MustCompile(`^[a-z_]+$`))
+
+	assertIsDefValid(t, id, map[string]interface{}{"foo": 1, "bar_baz": 2})
+	assertIsDefValid(t, id, map[string]interface{}{})
+
+	res := assertIsDefInvalid(t, id, map[string]interface{}{"foo": 1, "Bar": 2})
+	assert.True(t, res.Fields["p.foo"][0].Valid)
+	assert.False(t, res.Fields["p.Bar"][0].Valid)
+
+	assertIsDefInvalid(t, id, "foo")
+}
+
+func TestIsMapLengthBetween(t *testing.T) {
+	id := IsMapLengthBetween(1, 2)
+
+	res := assertIsDefValid(t, id, map[string]int{"a": 1})
+	assert.Equal(t, []llresult.ValueResult{llresult.ValidVR}, res.Fields["p.a"])
+	res = assertIsDefValid(t, id, map[int]int{1: 1, 2: 2})
+	assert.Contains(t, res.Fields, "p.1")
+	assert.Contains(t, res.Fields, "p.2")
+	assertIsDefInvalid(t, id, map[string]int{})
+	assertIsDefInvalid(t, id, map[string]int{"a": 1, "b": 2, "c": 3})
+	assertIsDefValid(t, IsMapLengthBetween(0, -1), map[string]int{"a": 1, "b": 2, "c": 3})
+}
+
+func TestIsMapOf(t *testing.T) {
+	id := IsMapOf(defValidator(IsIntGt(0)))
+
+	res := assertIsDefValid(t, id, map[string]int{"a": 1, "b": 2})
+	assert.Contains(t, res.Fields, "p.a")
+	assert.Contains(t, res.Fields, "p.b")
+
+	res = assertIsDefInvalid(t, id, map[string]interface{}{"a": 1, "b": -1})
+	assert.True(t, res.Fields["p.a"][0].Valid)
+	assert.False(t, res.Fields["p.b"][0].Valid)
+}
@@ -0,0 +1,255 @@
+package isdef
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+)
+
+// IPConstraint restricts the addresses accepted by IsIP.
+type IPConstraint struct {
+	name  string
+	check func(netip.Addr) bool
+}
+
+var (
+	// IPv4 only accepts IPv4 addresses, including IPv4-mapped IPv6 addresses.
+	IPv4 = IPConstraint{"IPv4", func(a netip.Addr) bool { return a.Unmap().Is4() }}
+	// IPv6 only accepts IPv6 addresses that are not IPv4-mapped.
+	IPv6 = IPConstraint{"IPv6", func(a netip.Addr) bool { return a.Is6() && !a.Is4In6() }}
+	// Loopback only accepts loopback addresses.
+	Loopback = IPConstraint{"loopback", netip.Addr.IsLoopback}
+	// Private only accepts private (RFC 1918 / RFC 4193) addresses.
+	Private = IPConstraint{"private", netip.Addr.IsPrivate}
+	// Global only accepts global unicast addresses.
+	Global = IPConstraint{"global unicast", netip.Addr.IsGlobalUnicast}
+)
+
+func (c IPConstraint) String() string {
+	return c.name
+}
+
+// toAddr converts the supported IP representations into a netip.Addr.
+func toAddr(v interface{}) (netip.Addr, error) {
+	switch ip := v.(type) {
+	case string:
+		return netip.ParseAddr(ip)
+	case netip.Addr:
+		if !ip.IsValid() {
+			return ip, fmt.Errorf("zero netip.Addr")
+		}
+		return ip, nil
+	case net.IP:
+		addr, ok := netip.AddrFromSlice(ip)
+		if !ok {
+			return addr, fmt.Errorf("net.IP has invalid length %d", len(ip))
+		}
+		// net.IP stores IPv4 addresses in their 16 byte form
+		if ip.To4() != nil {
+			addr = addr.Unmap()
+		}
+		return addr, nil
+	default:
+		return netip.Addr{}, fmt.Errorf("unsupported type %T", v)
+	}
+}
+
+// IsIP checks that the given value is an IP address, either as a string, a net.IP or a netip.Addr.
+// Any given constraints, such as IPv4 or Loopback, must all hold for the value to be valid.
+func IsIP(constraints ...IPConstraint) IsDef {
+	return Is("is an IP address", func(path llpath.Path, v interface{}) *llresult.Results {
+		addr, err := toAddr(v)
+		if err != nil {
+			return llresult.SimpleResult(path, false, "'%v' is not a valid IP address: %s", v, err)
+		}
+
+		for _, c := range constraints {
+			if !c.check(addr) {
+				return llresult.SimpleResult(path, false, "IP address '%s' is not %s", addr, c.name)
+			}
+		}
+
+		return llresult.ValidResult(path)
+	}).withSpec("isIP", toArgs(constraints)...)
+}
+
+// IsCIDR checks that the given value is a network prefix in CIDR notation, either as a string,
+// a netip.Prefix, a net.IPNet or a *net.IPNet.
+var IsCIDR = Is("is a CIDR", func(path llpath.Path, v interface{}) *llresult.Results {
+	var err error
+	switch p := v.(type) {
+	case string:
+		_, err = netip.ParsePrefix(p)
+	case netip.Prefix:
+		if !p.IsValid() {
+			err = fmt.Errorf("invalid netip.Prefix")
+		}
+	case net.IPNet:
+		_, err = netip.ParsePrefix(p.String())
+	case *net.IPNet:
+		if p == nil {
+			err = fmt.Errorf("nil *net.IPNet")
+		} else {
+			_, err = netip.ParsePrefix(p.String())
+		}
+	default:
+		err = fmt.Errorf("unsupported type %T", v)
+	}
+
+	if err != nil {
+		return llresult.SimpleResult(path, false, "'%v' is not a valid CIDR: %s", v, err)
+	}
+	return llresult.ValidResult(path)
+}).withSpec("isCIDR")
+
+// IsMAC checks that the given value is a hardware address, either as a string accepted by
+// net.ParseMAC or as a non-empty net.HardwareAddr.
+var IsMAC = Is("is a MAC address", func(path llpath.Path, v interface{}) *llresult.Results {
+	var err error
+	switch mac := v.(type) {
+	case string:
+		_, err = net.ParseMAC(mac)
+	case net.HardwareAddr:
+		if len(mac) == 0 {
+			err = fmt.Errorf("empty net.HardwareAddr")
+		}
+	default:
+		err = fmt.Errorf("unsupported type %T", v)
+	}
+
+	if err != nil {
+		return llresult.SimpleResult(path, false, "'%v' is not a valid MAC address: %s", v, err)
+	}
+	return llresult.ValidResult(path)
+}).withSpec("isMAC")
+
+// IsURL checks that the given value is an absolute URL, either as a string, a url.URL or a *url.URL.
+// If any schemes are given the URL's scheme must be one of them, compared case-insensitively.
+func IsURL(schemes ...string) IsDef {
+	return Is("is a URL", func(path llpath.Path, v interface{}) *llresult.Results {
+		var u *url.URL
+		var err error
+		switch uv := v.(type) {
+		case string:
+			u, err = url.Parse(uv)
+		case url.URL:
+			u = &uv
+		case *url.URL:
+			if uv == nil {
+				err = fmt.Errorf("nil *url.URL")
+			}
+			u = uv
+		default:
+			err = fmt.Errorf("unsupported type %T", v)
+		}
+
+		if err == nil && !u.IsAbs() {
+			err = fmt.Errorf("URL is not absolute")
+		}
+		if err != nil {
+			return llresult.SimpleResult(path, false, "'%v' is not a valid URL: %s", v, err)
+		}
+
+		if len(schemes) == 0 {
+			return llresult.ValidResult(path)
+		}
+		for _, s := range schemes {
+			if strings.EqualFold(u.Scheme, s) {
+				return llresult.ValidResult(path)
+			}
+		}
+		return llresult.SimpleResult(path, false, "URL '%s' has scheme '%s', expected one of %#v", u, u.Scheme, schemes)
+	}).withSpec("isURL", toArgs(schemes)...)
+}
+
+// IsHostname checks that the given value is a string that is a valid RFC 1123 hostname.
+// A single trailing dot, as used in fully qualified names, is allowed.
+var IsHostname = Is("is a hostname", func(path llpath.Path, v interface{}) *llresult.Results {
+	strV, errorResults := isStrCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	if err := checkHostname(strV); err != nil {
+		return llresult.SimpleResult(path, false, "'%s' is not a valid hostname: %s", strV, err)
+	}
+	return llresult.ValidResult(path)
+}).withSpec("isHostname")
+
+func checkHostname(host string) error {
+	host = strings.TrimSuffix(host, ".")
+	if len(host) == 0 {
+		return fmt.Errorf("hostname is empty")
+	}
+	if len(host) > 253 {
+		return fmt.Errorf("hostname is longer than 253 characters")
+	}
+
+	for _, label := range strings.Split(host, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return fmt.Errorf("label '%s' must be between 1 and 63 characters", label)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("label '%s' must not start or end with a hyphen", label)
+		}
+		for _, c := range label {
+			isAlnum := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+			if !isAlnum && c != '-' {
+				return fmt.Errorf("label '%s' contains invalid character %q", label, c)
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsPort checks that the given value is a valid TCP/UDP port number between 0 and 65535.
+// Any integer type, as well as a decimal string, is accepted.
+var IsPort = Is("is a port", func(path llpath.Path, v interface{}) *llresult.Results {
+	var port int64
+	switch p := v.(type) {
+	case int:
+		port = int64(p)
+	case int8:
+		port = int64(p)
+	case int16:
+		port = int64(p)
+	case int32:
+		port = int64(p)
+	case int64:
+		port = p
+	case uint:
+		port = int64(p)
+	case uint8:
+		port = int64(p)
+	case uint16:
+		port = int64(p)
+	case uint32:
+		port = int64(p)
+	case uint64:
+		if p > 65535 {
+			port = -1
+		} else {
+			port = int64(p)
+		}
+	case string:
+		parsed, err := strconv.ParseUint(p, 10, 16)
+		if err != nil {
+			return llresult.SimpleResult(path, false, "'%s' is not a valid port: %s", p, err)
+		}
+		port = int64(parsed)
+	default:
+		return llresult.SimpleResult(path, false, "%v is a %T, but was expecting a port number", v, v)
+	}
+
+	if port < 0 || port > 65535 {
+		return llresult.SimpleResult(path, false, "%v is not a valid port, expected 0-65535", v)
+	}
+	return llresult.ValidResult(path)
+}).withSpec("isPort")
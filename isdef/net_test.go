@@ -0,0 +1,110 @@
+package isdef
+
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"testing"
+)
+
+func TestIsIP(t *testing.T) {
+	id := IsIP()
+
+	assertIsDefValid(t, id, "10.0.0.1")
+	assertIsDefValid(t, id, "::1")
+	assertIsDefValid(t, id, net.ParseIP("192.168.1.1"))
+	assertIsDefValid(t, id, netip.MustParseAddr("fe80::1"))
+	assertIsDefInvalid(t, id, "10.0.0.256")
+	assertIsDefInvalid(t, id, "example.net")
+	assertIsDefInvalid(t, id, netip.Addr{})
+	assertIsDefInvalid(t, id, 123)
+}
+
+func TestIsIPConstraints(t *testing.T) {
+	v4 := IsIP(IPv4)
+	assertIsDefValid(t, v4, "127.0.0.1")
+	assertIsDefValid(t, v4, net.ParseIP("127.0.0.1"))
+	assertIsDefInvalid(t, v4, "::1")
+
+	v6 := IsIP(IPv6)
+	assertIsDefValid(t, v6, "::1")
+	assertIsDefInvalid(t, v6, "127.0.0.1")
+	assertIsDefInvalid(t, v6, net.ParseIP("127.0.0.1"))
+
+	v4Loopback := IsIP(IPv4, Loopback)
+	assertIsDefValid(t, v4Loopback, "127.0.0.1")
+	assertIsDefInvalid(t, v4Loopback, "::1")
+	assertIsDefInvalid(t, v4Loopback, "10.0.0.1")
+
+	assertIsDefValid(t, IsIP(Private), "192.168.0.1")
+	assertIsDefInvalid(t, IsIP(Private), "8.8.8.8")
+	assertIsDefValid(t, IsIP(Global), "8.8.8.8")
+}
+
+func TestIsCIDR(t *testing.T) {
+	_, ipNet, _ := net.ParseCIDR("10.0.0.0/8")
+
+	assertIsDefValid(t, IsCIDR, "10.0.0.0/8")
+	assertIsDefValid(t, IsCIDR, "2001:db8::/32")
+	assertIsDefValid(t, IsCIDR, netip.MustParsePrefix("192.168.0.0/16"))
+	assertIsDefValid(t, IsCIDR, ipNet)
+	assertIsDefValid(t, IsCIDR, *ipNet)
+	assertIsDefInvalid(t, IsCIDR, "10.0.0.0")
+	assertIsDefInvalid(t, IsCIDR, "10.0.0.0/33")
+	assertIsDefInvalid(t, IsCIDR, (*net.IPNet)(nil))
+	assertIsDefInvalid(t, IsCIDR, 123)
+}
+
+func TestIsMAC(t *testing.T) {
+	mac, _ := net.ParseMAC("00:00:5e:00:53:01")
+
+	assertIsDefValid(t, IsMAC, "00:00:5e:00:53:01")
+	assertIsDefValid(t, IsMAC, "00-00-5E-00-53-01")
+	assertIsDefValid(t, IsMAC, mac)
+	assertIsDefInvalid(t, IsMAC, "00:00:5e:00:53")
+	assertIsDefInvalid(t, IsMAC, net.HardwareAddr{})
+	assertIsDefInvalid(t, IsMAC, 123)
+}
+
+func TestIsURL(t *testing.T) {
+	u, _ := url.Parse("https://example.net/path")
+
+	assertIsDefValid(t, IsURL(), "https://example.net/path?q=1")
+	assertIsDefValid(t, IsURL(), "mailto:user@example.net")
+	assertIsDefValid(t, IsURL(), u)
+	assertIsDefValid(t, IsURL(), *u)
+	assertIsDefInvalid(t, IsURL(), "/relative/path")
+	assertIsDefInvalid(t, IsURL(), "http://[::1")
+	assertIsDefInvalid(t, IsURL(), (*url.URL)(nil))
+	assertIsDefInvalid(t, IsURL(), 123)
+
+	httpOnly := IsURL("http", "https")
+	assertIsDefValid(t, httpOnly, "HTTPS://example.net")
+	assertIsDefValid(t, httpOnly, u)
+	assertIsDefInvalid(t, httpOnly, "ftp://example.net")
+}
+
+func TestIsHostname(t *testing.T) {
+	assertIsDefValid(t, IsHostname, "localhost")
+	assertIsDefValid(t, IsHostname, "www.example.net")
+	assertIsDefValid(t, IsHostname, "www.example.net.")
+	assertIsDefValid(t, IsHostname, "xn--bcher-kva.example")
+	assertIsDefInvalid(t, IsHostname, "")
+	assertIsDefInvalid(t, IsHostname, "-bad.example.net")
+	assertIsDefInvalid(t, IsHostname, "bad..example.net")
+	assertIsDefInvalid(t, IsHostname, "under_score.example.net")
+	assertIsDefInvalid(t, IsHostname, 123)
+}
+
+func TestIsPort(t *testing.T) {
+	assertIsDefValid(t, IsPort, 0)
+	assertIsDefValid(t, IsPort, 443)
+	assertIsDefValid(t, IsPort, uint16(65535))
+	assertIsDefValid(t, IsPort, int64(8080))
+	assertIsDefValid(t, IsPort, "9200")
+	assertIsDefInvalid(t, IsPort, -1)
+	assertIsDefInvalid(t, IsPort, 65536)
+	assertIsDefInvalid(t, IsPort, uint64(1<<40))
+	assertIsDefInvalid(t, IsPort, "http")
+	assertIsDefInvalid(t, IsPort, 1.5)
+}
@@ -0,0 +1,49 @@
+package isdef
+
+import (
+	"fmt"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+)
+
+// Satisfies creates a named IsDef from a predicate over values of type T. Values that cannot be asserted
+// to T fail with the same message as IsType, so the predicate never has to check the type itself.
+//
+//	isEven := isdef.Satisfies("is even", func(n int) bool { return n%2 == 0 })
+func Satisfies[T any](name string, fn func(T) bool) IsDef {
+	return Is(name, func(path llpath.Path, v interface{}) *llresult.Results {
+		tv, ok := v.(T)
+		if !ok {
+			return typeMismatchResult[T](path, v)
+		}
+
+		if !fn(tv) {
+			return llresult.SimpleResult(path, false, fmt.Sprintf("Value '%v' did not satisfy '%s'", v, name))
+		}
+		return llresult.ValidResult(path)
+	})
+}
+
+// Check creates a named IsDef from a function over values of type T that returns a non-nil error
+// describing why a value is invalid. Values that cannot be asserted to T fail with the same message as IsType.
+//
+//	isFuture := isdef.Check("is in the future", func(t time.Time) error {
+//		if !t.After(time.Now()) {
+//			return fmt.Errorf("%s is not in the future", t)
+//		}
+//		return nil
+//	})
+func Check[T any](name string, fn func(T) error) IsDef {
+	return Is(name, func(path llpath.Path, v interface{}) *llresult.Results {
+		tv, ok := v.(T)
+		if !ok {
+			return typeMismatchResult[T](path, v)
+		}
+
+		if err := fn(tv); err != nil {
+			return llresult.SimpleResult(path, false, fmt.Sprintf("'%s' failed: %s", name, err))
+		}
+		return llresult.ValidResult(path)
+	})
+}
@@ -0,0 +1,40 @@
+package isdef
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSatisfies(t *testing.T) {
+	isEven := Satisfies("is even", func(n int) bool { return n%2 == 0 })
+
+	assertIsDefValid(t, isEven, 2)
+	res := assertIsDefInvalid(t, isEven, 3)
+	assert.Equal(t, "Value '3' did not satisfy 'is even'", res.Fields["p"][0].Message)
+
+	res = assertIsDefInvalid(t, isEven, "2")
+	assert.Equal(t, "Expected a int, got '2' which is a string", res.Fields["p"][0].Message)
+
+	// Predicates can also be used with interface types
+	isStringer := Satisfies("has short string", func(s fmt.Stringer) bool { return len(s.String()) < 5 })
+	assertIsDefInvalid(t, isStringer, nil)
+}
+
+func TestCheck(t *testing.T) {
+	isShort := Check("is short", func(s string) error {
+		if len(s) > 3 {
+			return fmt.Errorf("%d characters is too long", len(s))
+		}
+		return nil
+	})
+
+	assertIsDefValid(t, isShort, "abc")
+	res := assertIsDefInvalid(t, isShort, "abcd")
+	assert.Equal(t, "'is short' failed: 4 characters is too long", res.Fields["p"][0].Message)
+	assertIsDefInvalid(t, isShort, 1)
+
+	// Generic IsDefs plug into the other combinators like any IsDef
+	assertIsDefValid(t, IsAny(isShort, IsIntGt(0)), 1)
+}
@@ -0,0 +1,346 @@
+package isdef
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ParamType is the type of a parameter of a Matcher in a Registry. Arguments are converted to the
+// parameter's type before the Matcher is built, see Registry.Build.
+type ParamType int
+
+// The parameter types available to Matchers.
+const (
+	// ParamAny accepts any value as is.
+	ParamAny ParamType = iota
+	// ParamString accepts strings.
+	ParamString
+	// ParamInt accepts any integer, or a float without a fractional part, converted to an int.
+	ParamInt
+	// ParamNumber accepts any number, converted to a float64.
+	ParamNumber
+	// ParamBool accepts bools.
+	ParamBool
+	// ParamRegexp accepts a *regexp.Regexp, or a string which is compiled to one.
+	ParamRegexp
+	// ParamIsDef accepts IsDefs, which expressions write as nested matchers.
+	ParamIsDef
+)
+
+func (pt ParamType) String() string {
+	switch pt {
+	case ParamString:
+		return "string"
+	case ParamInt:
+		return "int"
+	case ParamNumber:
+		return "number"
+	case ParamBool:
+		return "bool"
+	case ParamRegexp:
+		return "regexp"
+	case ParamIsDef:
+		return "IsDef"
+	}
+	return "any"
+}
+
+// convert converts an argument, as decoded from JSON, YAML or an expression, to the parameter type.
+func (pt ParamType) convert(arg interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(arg)
+	switch pt {
+	case ParamString:
+		if s, ok := arg.(string); ok {
+			return s, nil
+		}
+	case ParamInt:
+		switch {
+		case isIntKind(rv.Kind()):
+			return int(rv.Int()), nil
+		case isUintKind(rv.Kind()):
+			return int(rv.Uint()), nil
+		case isFloatKind(rv.Kind()) && rv.Float() == math.Trunc(rv.Float()):
+			return int(rv.Float()), nil
+		}
+	case ParamNumber:
+		if isNumberKind(rv.Kind()) {
+			return toFloat(rv), nil
+		}
+	case ParamBool:
+		if b, ok := arg.(bool); ok {
+			return b, nil
+		}
+	case ParamRegexp:
+		switch re := arg.(type) {
+		case *regexp.Regexp:
+			return re, nil
+		case string:
+			return regexp.Compile(re)
+		}
+	case ParamIsDef:
+		if def, ok := arg.(IsDef); ok {
+			return def, nil
+		}
+	default:
+		return arg, nil
+	}
+	return nil, fmt.Errorf("expected %s, got '%v' which is a %T", pt, arg, arg)
+}
+
+// Matcher describes how to build an IsDef from a list of arguments, so it can be used by name in schemas that
+// are not written in Go.
+type Matcher struct {
+	// Name is how the Matcher is referred to, by convention the name of its constructor in lower camel case.
+	Name string
+	// Params are the types of the arguments Build receives.
+	Params []ParamType
+	// Variadic allows the last parameter to be repeated any number of times, including none.
+	Variadic bool
+	// Build creates the IsDef from arguments that have already been converted to the types of Params.
+	Build func(args []interface{}) (IsDef, error)
+}
+
+// Registry holds Matchers by name. A Registry is safe for concurrent use. Create one with NewRegistry.
+type Registry struct {
+	mu       sync.RWMutex
+	matchers map[string]Matcher
+}
+
+// NewRegistry creates a Registry containing the built-in matchers, to which custom ones can be added without
+// affecting DefaultRegistry.
+func NewRegistry() *Registry {
+	r := &Registry{matchers: map[string]Matcher{}}
+	for _, m := range builtinMatchers {
+		r.matchers[m.Name] = m
+	}
+	return r
+}
+
+// DefaultRegistry is the Registry used by BuildMatcher, ParseMatcher and RegisterMatcher.
+var DefaultRegistry = NewRegistry()
+
+// Register adds a Matcher to the Registry. It is an error to register a name twice.
+func (r *Registry) Register(m Matcher) error {
+	if !isIdentifier(m.Name) {
+		return fmt.Errorf("invalid matcher name '%s'", m.Name)
+	}
+	if m.Build == nil {
+		return fmt.Errorf("matcher '%s' has no Build function", m.Name)
+	}
+	if m.Variadic && len(m.Params) == 0 {
+		return fmt.Errorf("variadic matcher '%s' must have at least one parameter", m.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.matchers[m.Name]; exists {
+		return fmt.Errorf("a matcher named '%s' is already registered", m.Name)
+	}
+	if r.matchers == nil {
+		r.matchers = map[string]Matcher{}
+	}
+	r.matchers[m.Name] = m
+	return nil
+}
+
+// Lookup returns the Matcher registered with the given name.
+func (r *Registry) Lookup(name string) (Matcher, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.matchers[name]
+	return m, ok
+}
+
+// Names returns the names of all registered Matchers in order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.matchers))
+	for name := range r.matchers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Build creates an IsDef with the named Matcher, e.g. Build("isStringMatching", "^a"). Arguments are converted to
+// the Matcher's parameter types, so the types produced by decoding JSON or YAML are accepted: regexps may be given
+// as strings and ints as whole float64s. The IsDef's Spec is set to the name and arguments if Build did not set it.
+func (r *Registry) Build(name string, args ...interface{}) (IsDef, error) {
+	m, ok := r.Lookup(name)
+	if !ok {
+		return IsDef{}, fmt.Errorf("unknown matcher '%s'", name)
+	}
+
+	minArgs := len(m.Params)
+	if m.Variadic {
+		minArgs--
+	}
+	if len(args) < minArgs || (!m.Variadic && len(args) > len(m.Params)) {
+		expected := fmt.Sprintf("%d", len(m.Params))
+		if m.Variadic {
+			expected = fmt.Sprintf("at least %d", minArgs)
+		}
+		return IsDef{}, fmt.Errorf("matcher '%s' takes %s arguments, got %d", name, expected, len(args))
+	}
+
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		pt := m.Params[len(m.Params)-1]
+		if i < len(m.Params) {
+			pt = m.Params[i]
+		}
+		c, err := pt.convert(arg)
+		if err != nil {
+			return IsDef{}, fmt.Errorf("argument %d of matcher '%s': %w", i+1, name, err)
+		}
+		converted[i] = c
+	}
+
+	def, err := m.Build(converted)
+	if err != nil {
+		return IsDef{}, fmt.Errorf("matcher '%s': %w", name, err)
+	}
+	if def.Spec == nil {
+		def.Spec = &Spec{Matcher: name, Args: converted}
+	}
+	return def, nil
+}
+
+// MustRegister is the panic-ing equivalent of Register.
+func (r *Registry) MustRegister(m Matcher) {
+	if err := r.Register(m); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterMatcher adds a Matcher to DefaultRegistry.
+func RegisterMatcher(m Matcher) error {
+	return DefaultRegistry.Register(m)
+}
+
+// MustRegisterMatcher is the panic-ing equivalent of RegisterMatcher.
+func MustRegisterMatcher(m Matcher) {
+	DefaultRegistry.MustRegister(m)
+}
+
+// BuildMatcher creates an IsDef with the named Matcher from DefaultRegistry, see Registry.Build.
+func BuildMatcher(name string, args ...interface{}) (IsDef, error) {
+	return DefaultRegistry.Build(name, args...)
+}
+
+func fixed(name string, def IsDef) Matcher {
+	return Matcher{Name: name, Build: func([]interface{}) (IsDef, error) { return def, nil }}
+}
+
+func oneArg[T any](name string, pt ParamType, fn func(T) IsDef) Matcher {
+	return Matcher{Name: name, Params: []ParamType{pt}, Build: func(args []interface{}) (IsDef, error) {
+		// The assertion is unchecked so that nil can be passed for interface types
+		v, _ := args[0].(T)
+		return fn(v), nil
+	}}
+}
+
+func intRange(name string, fn func(min, max int) IsDef) Matcher {
+	return Matcher{Name: name, Params: []ParamType{ParamInt, ParamInt}, Build: func(args []interface{}) (IsDef, error) {
+		return fn(args[0].(int), args[1].(int)), nil
+	}}
+}
+
+func variadic[T any](name string, pt ParamType, fn func(...T) IsDef) Matcher {
+	return Matcher{Name: name, Params: []ParamType{pt}, Variadic: true, Build: func(args []interface{}) (IsDef, error) {
+		return fn(fromArgs[T](args)...), nil
+	}}
+}
+
+func fromArgs[T any](args []interface{}) []T {
+	values := make([]T, len(args))
+	for i, a := range args {
+		values[i], _ = a.(T)
+	}
+	return values
+}
+
+var ipConstraints = map[string]IPConstraint{
+	"ipv4": IPv4, "ipv6": IPv6, "loopback": Loopback, "private": Private, "global": Global, "global unicast": Global,
+}
+
+// builtinMatchers are the Matchers for the built-in IsDefs, named as in their Spec.Matcher.
+var builtinMatchers = []Matcher{
+	fixed("keyPresent", KeyPresent),
+	fixed("keyMissing", KeyMissing),
+	fixed("isNil", IsNil),
+	oneArg("isEqual", ParamAny, func(to interface{}) IsDef {
+		// IsEqual cannot look up an equality check for an untyped nil
+		if to == nil {
+			return IsNil
+		}
+		return IsEqual(to)
+	}),
+	oneArg("isDeepEqual", ParamAny, IsDeepEqual),
+	oneArg("isGt", ParamAny, IsGt),
+	oneArg("isGte", ParamAny, IsGte),
+	oneArg("isLt", ParamAny, IsLt),
+	oneArg("isLte", ParamAny, IsLte),
+	oneArg("isIntGt", ParamInt, IsIntGt),
+	fixed("isBool", IsBool),
+	fixed("isNumber", IsNumber),
+	fixed("isMap", IsMap),
+	fixed("isSlice", IsSlice),
+	fixed("isString", IsString),
+	fixed("isNonEmptyString", IsNonEmptyString),
+	fixed("isValidUTF8String", IsValidUTF8String),
+	oneArg("isStringMatching", ParamRegexp, IsStringMatching),
+	oneArg("isStringContaining", ParamString, IsStringContaining),
+	oneArg("isStringWithPrefix", ParamString, IsStringWithPrefix),
+	oneArg("isStringWithSuffix", ParamString, IsStringWithSuffix),
+	oneArg("isStringEqualFold", ParamString, IsStringEqualFold),
+	oneArg("isStringOfLength", ParamInt, IsStringOfLength),
+	intRange("isStringLengthBetween", IsStringLengthBetween),
+	variadic("isStringOneOf", ParamString, IsStringOneOf),
+	fixed("isUUID", IsUUID),
+	fixed("isEmail", IsEmail),
+	oneArg("isHex", ParamInt, IsHex),
+	fixed("isCIDR", IsCIDR),
+	fixed("isMAC", IsMAC),
+	variadic("isURL", ParamString, IsURL),
+	fixed("isHostname", IsHostname),
+	fixed("isPort", IsPort),
+	fixed("isDuration", IsDuration),
+	fixed("isNonEmptySlice", IsNonEmptySlice),
+	oneArg("isSliceOfLength", ParamInt, IsSliceOfLength),
+	intRange("isSliceLengthBetween", IsSliceLengthBetween),
+	variadic("isMapWithKeys", ParamString, IsMapWithKeys),
+	variadic("isMapWithExactKeys", ParamString, IsMapWithExactKeys),
+	oneArg("isMapKeysMatching", ParamRegexp, IsMapKeysMatching),
+	intRange("isMapLengthBetween", IsMapLengthBetween),
+	variadic("isAny", ParamIsDef, IsAny),
+	variadic("all", ParamIsDef, All),
+	variadic("exactlyOne", ParamIsDef, ExactlyOne),
+	oneArg("not", ParamIsDef, Not),
+	oneArg("sensitive", ParamIsDef, Sensitive),
+	oneArg("optional", ParamIsDef, Optional),
+	{Name: "isIP", Params: []ParamType{ParamString}, Variadic: true, Build: func(args []interface{}) (IsDef, error) {
+		constraints := make([]IPConstraint, len(args))
+		for i, a := range args {
+			c, ok := ipConstraints[strings.ToLower(a.(string))]
+			if !ok {
+				return IsDef{}, fmt.Errorf("unknown IP constraint '%s'", a)
+			}
+			constraints[i] = c
+		}
+		return IsIP(constraints...), nil
+	}},
+	{Name: "isJSONType", Params: []ParamType{ParamString}, Variadic: true, Build: func(args []interface{}) (IsDef, error) {
+		types := make([]JSONType, len(args))
+		for i, a := range args {
+			types[i] = JSONType(a.(string))
+		}
+		return IsJSONType(types...), nil
+	}},
+}
@@ -0,0 +1,66 @@
+package isdef
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMatcher(t *testing.T) {
+	id, err := BuildMatcher("isStringMatching", "^a")
+	require.NoError(t, err)
+	assertIsDefValid(t, id, "abc")
+	assertIsDefInvalid(t, id, "cba")
+
+	// Whole floats, as decoded from JSON, are accepted for int parameters
+	id, err = BuildMatcher("isStringLengthBetween", 1.0, 2)
+	require.NoError(t, err)
+	assertIsDefValid(t, id, "ab")
+	assertIsDefInvalid(t, id, "abc")
+
+	id, err = BuildMatcher("isStringOneOf", "a", "b")
+	require.NoError(t, err)
+	assertIsDefValid(t, id, "b")
+	assertIsDefInvalid(t, id, "c")
+
+	id, err = BuildMatcher("isIP", "IPv4")
+	require.NoError(t, err)
+	assertIsDefValid(t, id, "127.0.0.1")
+	assertIsDefInvalid(t, id, "::1")
+
+	id, err = BuildMatcher("isEqual", nil)
+	require.NoError(t, err)
+	assertIsDefValid(t, id, nil)
+
+	id, err = BuildMatcher("isAny", IsNil, IsString)
+	require.NoError(t, err)
+	assertIsDefValid(t, id, "x")
+	assertIsDefInvalid(t, id, 1)
+
+	id, err = BuildMatcher("isUUID")
+	require.NoError(t, err)
+	assert.Equal(t, "isUUID", id.Spec.Matcher)
+}
+
+func TestBuildMatcherErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args []interface{}
+		err  string
+	}{
+		{"isNothing", nil, "unknown matcher 'isNothing'"},
+		{"isString", []interface{}{1}, "matcher 'isString' takes 0 arguments, got 1"},
+		{"isStringLengthBetween", []interface{}{1}, "matcher 'isStringLengthBetween' takes 2 arguments, got 1"},
+		{"isStringOfLength", []interface{}{1.5}, "argument 1 of matcher 'isStringOfLength': expected int, got '1.5' which is a float64"},
+		{"isStringMatching", []interface{}{"("}, "argument 1 of matcher 'isStringMatching': error parsing regexp"},
+		{"isIP", []interface{}{"public"}, "unknown IP constraint 'public'"},
+		{"not", []interface{}{"x"}, "argument 1 of matcher 'not': expected IsDef"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := BuildMatcher(tc.name, tc.args...)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
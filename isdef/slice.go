@@ -0,0 +1,347 @@
+package isdef
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+	"github.com/elastic/go-lookslike/validator"
+)
+
+// isSliceCheck is a helper for IsDefs that must assert that the value is a slice or array first.
+func isSliceCheck(path llpath.Path, v interface{}) (elems []interface{}, errorResults *llresult.Results) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, llresult.SimpleResult(
+			path,
+			false,
+			fmt.Sprintf("Expected a slice, got '%v' which is a %T", v, v),
+		)
+	}
+
+	elems = make([]interface{}, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		elem := rv.Index(i)
+		if elem.Kind() == reflect.Interface && elem.IsNil() {
+			continue
+		}
+		elems[i] = elem.Interface()
+	}
+	return elems, nil
+}
+
+// validSliceResult returns a valid result for the slice at the given path that also records each element
+// without a result of its own in other as valid, so that Strict does not report elements the check accepted.
+func validSliceResult(path llpath.Path, elems []interface{}, other *llresult.Results) *llresult.Results {
+	res := llresult.ValidResult(path)
+	if other != nil {
+		res.Merge(other)
+	}
+	for idx := range elems {
+		if _, ok := res.Fields[path.ExtendSlice(idx).String()]; !ok {
+			res.Record(path.ExtendSlice(idx), llresult.ValidVR)
+		}
+	}
+	return res
+}
+
+// IsNonEmptySlice checks that the given value is a slice or array with at least one element.
+var IsNonEmptySlice = Is("is a non-empty slice", func(path llpath.Path, v interface{}) *llresult.Results {
+	elems, errorResults := isSliceCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	if len(elems) == 0 {
+		return llresult.SimpleResult(path, false, "Slice should not be empty")
+	}
+	return validSliceResult(path, elems, nil)
+}).withSpec("isNonEmptySlice")
+
+// IsSliceOfLength checks that the given value is a slice or array with exactly the given number of elements.
+func IsSliceOfLength(length int) IsDef {
+	return Is("is slice of length", sliceLengthChecker(length, length)).withSpec("isSliceOfLength", length)
+}
+
+// IsSliceLengthBetween checks that the given value is a slice or array with between min and max elements
+// inclusive. A negative max means there is no upper bound.
+func IsSliceLengthBetween(min, max int) IsDef {
+	return Is("is slice with length between", sliceLengthChecker(min, max)).withSpec("isSliceLengthBetween", min, max)
+}
+
+func sliceLengthChecker(min, max int) ValueValidator {
+	return func(path llpath.Path, v interface{}) *llresult.Results {
+		elems, errorResults := isSliceCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		length := len(elems)
+		if expected, ok := lengthBetween(length, min, max); !ok {
+			return llresult.SimpleResult(
+				path,
+				false,
+				fmt.Sprintf("Slice has length %d, expected length %s", length, expected),
+			)
+		}
+		return validSliceResult(path, elems, nil)
+	}
+}
+
+// IsSliceContaining checks that at least one element of the slice at the given path is valid for the
+// given validator.Validator. The results for the first matching element are recorded under its index, and
+// the other elements are recorded as valid. If no element matches, the results for every element are recorded under their indices.
+func IsSliceContaining(validator validator.Validator) IsDef {
+	return Is("slice containing", func(path llpath.Path, v interface{}) *llresult.Results {
+		elems, errorResults := isSliceCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		elemResults := make([]*llresult.Results, len(elems))
+		for idx, elem := range elems {
+			elemResults[idx] = validator(elem)
+			if elemResults[idx].Valid {
+				res := llresult.NewResults()
+				res.MergeUnderPrefix(path.ExtendSlice(idx), elemResults[idx])
+				return validSliceResult(path, elems, res)
+			}
+		}
+
+		res := llresult.SimpleResult(
+			path,
+			false,
+			fmt.Sprintf("None of the %d elements in the slice matched", len(elems)),
+		)
+		for idx, elemRes := range elemResults {
+			res.MergeUnderPrefix(path.ExtendSlice(idx), elemRes)
+		}
+		return res
+	}).withSpec("isSliceContaining", validator)
+}
+
+// IsSliceContainingAll checks that every given validator.Validator is matched by a different element of
+// the slice, in any order. Other elements are allowed. The results of each matched element are recorded
+// under its index, and when every validator is matched the other elements are recorded as valid.
+func IsSliceContainingAll(validators ...validator.Validator) IsDef {
+	return Is("slice containing all", func(path llpath.Path, v interface{}) *llresult.Results {
+		elems, errorResults := isSliceCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		m := matchUnordered(validators, elems)
+		res := llresult.ValidResult(path)
+		m.recordMatched(path, res)
+		m.recordUnmatchedValidators(path, res)
+		if !res.Valid {
+			return res
+		}
+		return validSliceResult(path, elems, res)
+	})
+}
+
+// IsSliceUnorderedEqual checks that the slice has exactly one element for each given validator.Validator,
+// in any order. Elements that match no validator are recorded as failures under their index.
+func IsSliceUnorderedEqual(validators ...validator.Validator) IsDef {
+	return Is("slice unordered equal", func(path llpath.Path, v interface{}) *llresult.Results {
+		elems, errorResults := isSliceCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		m := matchUnordered(validators, elems)
+		res := llresult.ValidResult(path)
+		if len(elems) != len(validators) {
+			res.Record(path, llresult.ValueResult{
+				Valid:   false,
+				Message: fmt.Sprintf("Slice has length %d, expected length %d", len(elems), len(validators)),
+			})
+		}
+		m.recordMatched(path, res)
+		m.recordUnmatchedValidators(path, res)
+		for idx, vIdx := range m.elemToValidator {
+			if vIdx < 0 {
+				res.Record(path.ExtendSlice(idx), llresult.ValueResult{
+					Valid:   false,
+					Message: fmt.Sprintf("Element %#v did not match any expected element", elems[idx]),
+				})
+			}
+		}
+		return res
+	})
+}
+
+// unorderedMatch is the maximum matching between validators and the elements they are valid for.
+type unorderedMatch struct {
+	results         [][]*llresult.Results // indexed by validator, then element
+	validatorToElem []int
+	elemToValidator []int
+}
+
+// matchUnordered pairs validators with distinct elements, using augmenting paths so that an early
+// greedy choice never prevents a later validator from being matched.
+func matchUnordered(validators []validator.Validator, elems []interface{}) unorderedMatch {
+	m := unorderedMatch{
+		results:         make([][]*llresult.Results, len(validators)),
+		validatorToElem: make([]int, len(validators)),
+		elemToValidator: make([]int, len(elems)),
+	}
+	for vIdx, validator := range validators {
+		m.results[vIdx] = make([]*llresult.Results, len(elems))
+		for eIdx, elem := range elems {
+			m.results[vIdx][eIdx] = validator(elem)
+		}
+		m.validatorToElem[vIdx] = -1
+	}
+	for eIdx := range elems {
+		m.elemToValidator[eIdx] = -1
+	}
+
+	for vIdx := range validators {
+		m.augment(vIdx, make([]bool, len(elems)))
+	}
+	return m
+}
+
+func (m *unorderedMatch) augment(vIdx int, seen []bool) bool {
+	for eIdx, res := range m.results[vIdx] {
+		if !res.Valid || seen[eIdx] {
+			continue
+		}
+		seen[eIdx] = true
+		if m.elemToValidator[eIdx] < 0 || m.augment(m.elemToValidator[eIdx], seen) {
+			m.elemToValidator[eIdx] = vIdx
+			m.validatorToElem[vIdx] = eIdx
+			return true
+		}
+	}
+	return false
+}
+
+func (m unorderedMatch) recordMatched(path llpath.Path, res *llresult.Results) {
+	for vIdx, eIdx := range m.validatorToElem {
+		if eIdx >= 0 {
+			res.MergeUnderPrefix(path.ExtendSlice(eIdx), m.results[vIdx][eIdx])
+		}
+	}
+}
+
+func (m unorderedMatch) recordUnmatchedValidators(path llpath.Path, res *llresult.Results) {
+	for vIdx, eIdx := range m.validatorToElem {
+		if eIdx < 0 {
+			res.Record(path, llresult.ValueResult{
+				Valid:   false,
+				Message: fmt.Sprintf("No element matched expected element %d", vIdx),
+			})
+		}
+	}
+}
+
+// IsSliceSorted checks that the elements of the slice are in non-decreasing order. Elements must all
+// be numbers, all be strings, or all be time.Times.
+var IsSliceSorted = IsSliceSortedBy("is sorted slice", defaultLess)
+
+// IsSliceSortedBy checks that the elements of the slice are in non-decreasing order according to the
+// given less function. Each element that is less than its predecessor is recorded as a failure under its index.
+func IsSliceSortedBy(name string, less func(a, b interface{}) (bool, error)) IsDef {
+	return Is(name, func(path llpath.Path, v interface{}) *llresult.Results {
+		elems, errorResults := isSliceCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		res := llresult.ValidResult(path)
+		for idx := 1; idx < len(elems); idx++ {
+			isLess, err := less(elems[idx], elems[idx-1])
+			if err != nil {
+				res.Record(path.ExtendSlice(idx), llresult.ValueResult{
+					Valid:   false,
+					Message: fmt.Sprintf("Could not compare elements: %s", err),
+				})
+			} else if isLess {
+				res.Record(path.ExtendSlice(idx), llresult.ValueResult{
+					Valid:   false,
+					Message: fmt.Sprintf("Element %#v is out of order, it is less than previous element %#v", elems[idx], elems[idx-1]),
+				})
+			}
+		}
+		if !res.Valid {
+			return res
+		}
+		return validSliceResult(path, elems, res)
+	})
+}
+
+// defaultLess orders numbers, strings and times using compareValues.
+func defaultLess(a, b interface{}) (bool, error) {
+	cmp, err := compareValues(a, b)
+	return cmp < 0, err
+}
+
+// compareValues returns -1, 0 or 1 as a is less than, equal to or greater than b. Numbers of any kind
+// can be compared with each other, as can two strings or two time.Times.
+func compareValues(a, b interface{}) (int, error) {
+	if at, ok := a.(time.Time); ok {
+		if bt, ok := b.(time.Time); ok {
+			switch {
+			case at.Before(bt):
+				return -1, nil
+			case at.After(bt):
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case isIntKind(av.Kind()) && isIntKind(bv.Kind()):
+		return compareOrdered(av.Int(), bv.Int()), nil
+	case isUintKind(av.Kind()) && isUintKind(bv.Kind()):
+		return compareOrdered(av.Uint(), bv.Uint()), nil
+	case isNumberKind(av.Kind()) && isNumberKind(bv.Kind()):
+		return compareOrdered(toFloat(av), toFloat(bv)), nil
+	case av.Kind() == reflect.String && bv.Kind() == reflect.String:
+		return compareOrdered(av.String(), bv.String()), nil
+	}
+	return 0, fmt.Errorf("cannot order %T and %T", a, b)
+}
+
+func compareOrdered[T int64 | uint64 | float64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch {
+	case isIntKind(v.Kind()):
+		return float64(v.Int())
+	case isUintKind(v.Kind()):
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	return isIntKind(k) || isUintKind(k) || isFloatKind(k)
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
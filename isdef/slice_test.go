@@ -0,0 +1,114 @@
+package isdef
+
+import (
+	"testing"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// defValidator adapts an IsDef for use where a validator.Validator is required.
+func defValidator(id IsDef) func(interface{}) *llresult.Results {
+	return func(actual interface{}) *llresult.Results {
+		return id.Check(llpath.Path{}, actual, true)
+	}
+}
+
+func TestIsNonEmptySlice(t *testing.T) {
+	res := assertIsDefValid(t, IsNonEmptySlice, []int{1, 2})
+	assert.Equal(t, []llresult.ValueResult{llresult.ValidVR}, res.Fields["p.[0]"])
+	assert.Equal(t, []llresult.ValueResult{llresult.ValidVR}, res.Fields["p.[1]"])
+
+	assertIsDefValid(t, IsNonEmptySlice, [1]string{"a"})
+	assertIsDefInvalid(t, IsNonEmptySlice, []int{})
+	assertIsDefInvalid(t, IsNonEmptySlice, "abc")
+	assertIsDefInvalid(t, IsNonEmptySlice, nil)
+}
+
+func TestIsSliceLength(t *testing.T) {
+	res := assertIsDefValid(t, IsSliceOfLength(2), []string{"a", "b"})
+	assert.Contains(t, res.Fields, "p.[0]")
+	assert.Contains(t, res.Fields, "p.[1]")
+	assertIsDefInvalid(t, IsSliceOfLength(2), []string{"a"})
+
+	between := IsSliceLengthBetween(1, 2)
+	assertIsDefValid(t, between, []int{1})
+	assertIsDefValid(t, between, []int{1, 2})
+	assertIsDefInvalid(t, between, []int{})
+	assertIsDefInvalid(t, between, []int{1, 2, 3})
+	assertIsDefValid(t, IsSliceLengthBetween(1, -1), []int{1, 2, 3})
+}
+
+func TestIsSliceContaining(t *testing.T) {
+	id := IsSliceContaining(defValidator(IsStringContaining("b")))
+
+	res := assertIsDefValid(t, id, []interface{}{"a", "b", "bb"})
+	assert.Contains(t, res.Fields, "p.[1]")
+	// Elements after the match are not checked, but are recorded as valid so Strict accepts them
+	assert.Equal(t, []llresult.ValueResult{llresult.ValidVR}, res.Fields["p.[0]"])
+	assert.Equal(t, []llresult.ValueResult{llresult.ValidVR}, res.Fields["p.[2]"])
+
+	res = assertIsDefInvalid(t, id, []string{"a", "c"})
+	assert.False(t, res.Fields["p"][0].Valid)
+	assert.False(t, res.Fields["p.[0]"][0].Valid)
+	assert.False(t, res.Fields["p.[1]"][0].Valid)
+
+	assertIsDefInvalid(t, id, []string{})
+	assertIsDefInvalid(t, id, "b")
+}
+
+func TestIsSliceContainingAll(t *testing.T) {
+	id := IsSliceContainingAll(
+		defValidator(IsStringContaining("a")),
+		defValidator(IsEqual("ab")),
+	)
+
+	// A greedy match would pair "ab" with the first validator and fail the second
+	res := assertIsDefValid(t, id, []string{"ab", "xa", "z"})
+	assert.Contains(t, res.Fields, "p.[0]")
+	assert.Contains(t, res.Fields, "p.[1]")
+	assert.Equal(t, []llresult.ValueResult{llresult.ValidVR}, res.Fields["p.[2]"])
+
+	assertIsDefValid(t, id, []string{"a", "ab"})
+
+	res = assertIsDefInvalid(t, id, []string{"ab"})
+	require.Len(t, res.Errors(), 1)
+	assert.Contains(t, res.Errors()[0].Error(), "No element matched expected element")
+}
+
+func TestIsSliceUnorderedEqual(t *testing.T) {
+	id := IsSliceUnorderedEqual(
+		defValidator(IsEqual(1)),
+		defValidator(IsEqual(2)),
+		defValidator(IsEqual(2)),
+	)
+
+	assertIsDefValid(t, id, []int{2, 1, 2})
+	assertIsDefValid(t, id, []interface{}{2, 2, 1})
+	assertIsDefInvalid(t, id, []int{1, 2})
+	assertIsDefInvalid(t, id, []int{1, 1, 2})
+
+	res := assertIsDefInvalid(t, id, []int{2, 1, 2, 3})
+	assert.False(t, res.Fields["p.[3]"][0].Valid)
+}
+
+func TestIsSliceSorted(t *testing.T) {
+	res := assertIsDefValid(t, IsSliceSorted, []int{1, 2, 2, 3})
+	assert.Len(t, res.Fields, 5)
+	assertIsDefValid(t, IsSliceSorted, []string{"a", "b"})
+	assertIsDefValid(t, IsSliceSorted, []float64{})
+
+	res = assertIsDefInvalid(t, IsSliceSorted, []int{1, 3, 2, 4})
+	assert.False(t, res.Fields["p.[2]"][0].Valid)
+	assert.NotContains(t, res.Fields, "p.[3]")
+
+	assertIsDefInvalid(t, IsSliceSorted, []interface{}{1, "a"})
+
+	desc := IsSliceSortedBy("is descending", func(a, b interface{}) (bool, error) {
+		return a.(int) > b.(int), nil
+	})
+	assertIsDefValid(t, desc, []int{3, 2, 1})
+	assertIsDefInvalid(t, desc, []int{1, 2})
+}
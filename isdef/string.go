@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/elastic/go-lookslike/llpath"
 	"github.com/elastic/go-lookslike/llresult"
@@ -32,7 +33,7 @@ var IsString = Is("is a string", func(path llpath.Path, v interface{}) *llresult
 	}
 
 	return llresult.ValidResult(path)
-})
+}).withSpec("isString")
 
 // IsNonEmptyString checks that the given value is a string and has a length > 1.
 var IsNonEmptyString = Is("is a non-empty string", func(path llpath.Path, v interface{}) *llresult.Results {
@@ -46,7 +47,7 @@ var IsNonEmptyString = Is("is a non-empty string", func(path llpath.Path, v inte
 	}
 
 	return llresult.ValidResult(path)
-})
+}).withSpec("isNonEmptyString")
 
 // IsStringMatching checks whether a value matches the given regexp.
 func IsStringMatching(regexp *regexp.Regexp) IsDef {
@@ -65,7 +66,7 @@ func IsStringMatching(regexp *regexp.Regexp) IsDef {
 		}
 
 		return llresult.ValidResult(path)
-	})
+	}).withSpec("isStringMatching", regexp)
 }
 
 // IsStringContaining validates that the the actual value contains the specified substring.
@@ -85,5 +86,137 @@ func IsStringContaining(needle string) IsDef {
 		}
 
 		return llresult.ValidResult(path)
-	})
+	}).withSpec("isStringContaining", needle)
 }
+
+// IsStringWithPrefix validates that the actual value starts with the specified prefix.
+func IsStringWithPrefix(prefix string) IsDef {
+	return Is("is string with prefix", func(path llpath.Path, v interface{}) *llresult.Results {
+		strV, errorResults := isStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if !strings.HasPrefix(strV, prefix) {
+			return llresult.SimpleResult(
+				path,
+				false,
+				fmt.Sprintf("String '%s' did not start with prefix '%s'", strV, prefix),
+			)
+		}
+
+		return llresult.ValidResult(path)
+	}).withSpec("isStringWithPrefix", prefix)
+}
+
+// IsStringWithSuffix validates that the actual value ends with the specified suffix.
+func IsStringWithSuffix(suffix string) IsDef {
+	return Is("is string with suffix", func(path llpath.Path, v interface{}) *llresult.Results {
+		strV, errorResults := isStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if !strings.HasSuffix(strV, suffix) {
+			return llresult.SimpleResult(
+				path,
+				false,
+				fmt.Sprintf("String '%s' did not end with suffix '%s'", strV, suffix),
+			)
+		}
+
+		return llresult.ValidResult(path)
+	}).withSpec("isStringWithSuffix", suffix)
+}
+
+// IsStringOfLength validates that the actual value is a string of exactly the given length,
+// counted in runes rather than bytes.
+func IsStringOfLength(length int) IsDef {
+	return Is("is string of length", stringLengthChecker(length, length)).withSpec("isStringOfLength", length)
+}
+
+// IsStringLengthBetween validates that the actual value is a string whose length, counted in runes,
+// is between min and max inclusive. A negative max means there is no upper bound.
+func IsStringLengthBetween(min, max int) IsDef {
+	return Is("is string with length between", stringLengthChecker(min, max)).withSpec("isStringLengthBetween", min, max)
+}
+
+func stringLengthChecker(min, max int) ValueValidator {
+	return func(path llpath.Path, v interface{}) *llresult.Results {
+		strV, errorResults := isStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		length := utf8.RuneCountInString(strV)
+		if expected, ok := lengthBetween(length, min, max); !ok {
+			return llresult.SimpleResult(
+				path,
+				false,
+				fmt.Sprintf("String '%s' has length %d, expected length %s", strV, length, expected),
+			)
+		}
+
+		return llresult.ValidResult(path)
+	}
+}
+
+// IsStringEqualFold validates that the actual value is equal to the given string, ignoring case.
+// Case folding follows strings.EqualFold.
+func IsStringEqualFold(to string) IsDef {
+	return Is("is string equal ignoring case", func(path llpath.Path, v interface{}) *llresult.Results {
+		strV, errorResults := isStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if !strings.EqualFold(strV, to) {
+			return llresult.SimpleResult(
+				path,
+				false,
+				fmt.Sprintf("String '%s' is not equal to '%s' ignoring case", strV, to),
+			)
+		}
+
+		return llresult.ValidResult(path)
+	}).withSpec("isStringEqualFold", to)
+}
+
+// IsStringOneOf validates that the actual value is exactly one of the given strings.
+func IsStringOneOf(options ...string) IsDef {
+	allowed := make(map[string]struct{}, len(options))
+	for _, o := range options {
+		allowed[o] = struct{}{}
+	}
+
+	return Is("is string one of", func(path llpath.Path, v interface{}) *llresult.Results {
+		strV, errorResults := isStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if _, ok := allowed[strV]; !ok {
+			return llresult.SimpleResult(
+				path,
+				false,
+				fmt.Sprintf("String '%s' was not one of %#v", strV, options),
+			)
+		}
+
+		return llresult.ValidResult(path)
+	}).withSpec("isStringOneOf", toArgs(options)...)
+}
+
+// IsValidUTF8String checks that the given value is a string containing only valid UTF-8 sequences.
+var IsValidUTF8String = Is("is a valid utf-8 string", func(path llpath.Path, v interface{}) *llresult.Results {
+	strV, errorResults := isStrCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	if !utf8.ValidString(strV) {
+		return llresult.SimpleResult(path, false, "String %q is not valid UTF-8", strV)
+	}
+
+	return llresult.ValidResult(path)
+}).withSpec("isValidUTF8String")
@@ -34,3 +34,69 @@ func TestIsStringContaining(t *testing.T) {
 	assertIsDefInvalid(t, id, "a bar b")
 	assertIsDefInvalid(t, IsString, 123)
 }
+
+func TestIsStringWithPrefix(t *testing.T) {
+	id := IsStringWithPrefix("http")
+
+	assertIsDefValid(t, id, "http://example.net")
+	assertIsDefValid(t, id, "http")
+	assertIsDefInvalid(t, id, "ftp://example.net")
+	assertIsDefInvalid(t, id, 123)
+}
+
+func TestIsStringWithSuffix(t *testing.T) {
+	id := IsStringWithSuffix(".log")
+
+	assertIsDefValid(t, id, "system.log")
+	assertIsDefInvalid(t, id, "system.log.gz")
+	assertIsDefInvalid(t, id, 123)
+}
+
+func TestIsStringOfLength(t *testing.T) {
+	id := IsStringOfLength(3)
+
+	assertIsDefValid(t, id, "abc")
+	// Length is counted in runes, not bytes
+	assertIsDefValid(t, id, "héé")
+	assertIsDefInvalid(t, id, "ab")
+	assertIsDefInvalid(t, id, "abcd")
+	assertIsDefInvalid(t, id, 123)
+}
+
+func TestIsStringLengthBetween(t *testing.T) {
+	id := IsStringLengthBetween(2, 4)
+
+	assertIsDefValid(t, id, "ab")
+	assertIsDefValid(t, id, "abcd")
+	assertIsDefInvalid(t, id, "a")
+	assertIsDefInvalid(t, id, "abcde")
+
+	unbounded := IsStringLengthBetween(2, -1)
+	assertIsDefValid(t, unbounded, "a very long string indeed")
+	assertIsDefInvalid(t, unbounded, "a")
+}
+
+func TestIsStringEqualFold(t *testing.T) {
+	id := IsStringEqualFold("GET")
+
+	assertIsDefValid(t, id, "GET")
+	assertIsDefValid(t, id, "get")
+	assertIsDefInvalid(t, id, "POST")
+	assertIsDefInvalid(t, id, 123)
+}
+
+func TestIsStringOneOf(t *testing.T) {
+	id := IsStringOneOf("up", "down")
+
+	assertIsDefValid(t, id, "up")
+	assertIsDefValid(t, id, "down")
+	assertIsDefInvalid(t, id, "Up")
+	assertIsDefInvalid(t, id, "")
+	assertIsDefInvalid(t, id, 123)
+}
+
+func TestIsValidUTF8String(t *testing.T) {
+	assertIsDefValid(t, IsValidUTF8String, "héllo")
+	assertIsDefInvalid(t, IsValidUTF8String, "\xff\xfe")
+	assertIsDefInvalid(t, IsValidUTF8String, 123)
+}
@@ -0,0 +1,278 @@
+package isdef
+
+import (
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"reflect"
+	"sync"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+)
+
+// IsUnique instances are used in multiple spots, flagging a value as being in error if it's seen across invocations.
+// To use it, assign IsUnique to a variable, then use that variable multiple times in a map[string]interface{}.
+func IsUnique() IsDef {
+	return ScopedIsUnique().IsUniqueTo("")
+}
+
+// UniqScopeTracker is the previous name of Tracker, kept for compatibility.
+type UniqScopeTracker = Tracker
+
+// ScopedIsUnique returns a new scope for uniqueness checks.
+func ScopedIsUnique() *UniqScopeTracker {
+	return NewTracker()
+}
+
+// Tracker remembers values across checks, and so across documents, to validate how the values in a
+// sequence of documents relate to each other. IsDefs created by the same Tracker share its state.
+// A Tracker is safe for concurrent use, and its zero value is ready to use.
+type Tracker struct {
+	mu sync.Mutex
+	// unique maps values to the namespace they were first seen in
+	unique valueSet
+	// last holds the most recently seen value per series for IsIncreasing and IsNonDecreasing
+	last map[string]interface{}
+	// first holds the first seen value per series for IsSameAsFirst
+	first map[string]interface{}
+	// recorded holds the values recorded per set by Record
+	recorded map[string]*valueSet
+}
+
+// NewTracker returns a new, empty Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// Reset forgets every value seen by the Tracker.
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.unique = valueSet{}
+	t.last = nil
+	t.first = nil
+	t.recorded = nil
+}
+
+// IsUniqueTo validates that the given value is only ever seen within a single namespace.
+// With an empty namespace the value may only be seen once.
+func (t *Tracker) IsUniqueTo(namespace string) IsDef {
+	return Is("unique", func(path llpath.Path, v interface{}) *llresult.Results {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		if seenIn, seen := t.unique.get(v); seen {
+			if len(namespace) == 0 || namespace != seenIn {
+				return llresult.SimpleResult(path, false, "Value '%v' is repeated", v)
+			}
+			return llresult.ValidResult(path)
+		}
+
+		t.unique.add(v, namespace)
+		return llresult.ValidResult(path)
+	})
+}
+
+// IsIncreasing validates that each value in the named series is strictly greater than the one
+// checked before it. Numbers, strings and time.Times can be compared.
+func (t *Tracker) IsIncreasing(series string) IsDef {
+	return t.seriesComparison("increasing", series, func(cmp int) bool { return cmp > 0 })
+}
+
+// IsNonDecreasing validates that each value in the named series is greater than or equal to the one
+// checked before it.
+func (t *Tracker) IsNonDecreasing(series string) IsDef {
+	return t.seriesComparison("non-decreasing", series, func(cmp int) bool { return cmp >= 0 })
+}
+
+func (t *Tracker) seriesComparison(name string, series string, valid func(cmp int) bool) IsDef {
+	return Is(name, func(path llpath.Path, v interface{}) *llresult.Results {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		prev, seen := t.last[series]
+		if !seen {
+			if t.last == nil {
+				t.last = map[string]interface{}{}
+			}
+			t.last[series] = v
+			return llresult.ValidResult(path)
+		}
+
+		cmp, err := compareValues(v, prev)
+		if err != nil {
+			return llresult.SimpleResult(path, false, fmt.Sprintf("Could not compare with previous value: %s", err))
+		}
+		if !valid(cmp) {
+			return llresult.SimpleResult(
+				path,
+				false,
+				fmt.Sprintf("Value '%v' is not %s, previous value in series '%s' was '%v'", v, name, series, prev),
+			)
+		}
+
+		t.last[series] = v
+		return llresult.ValidResult(path)
+	})
+}
+
+// IsSameAsFirst validates that every value in the named series is deeply equal to the first one checked.
+func (t *Tracker) IsSameAsFirst(series string) IsDef {
+	return Is("same as first", func(path llpath.Path, v interface{}) *llresult.Results {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		first, seen := t.first[series]
+		if !seen {
+			if t.first == nil {
+				t.first = map[string]interface{}{}
+			}
+			t.first[series] = v
+			return llresult.ValidResult(path)
+		}
+
+		if !reflect.DeepEqual(v, first) {
+			return llresult.SimpleResult(
+				path,
+				false,
+				fmt.Sprintf("Value '%v' differs from the first value in series '%s', '%v'", v, series, first),
+			)
+		}
+		return llresult.ValidResult(path)
+	})
+}
+
+// Record remembers every value it checks in the named set, so later values can refer to it with
+// IsRecordedIn. It is always valid.
+func (t *Tracker) Record(set string) IsDef {
+	return Is("record", func(path llpath.Path, v interface{}) *llresult.Results {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		if t.recorded == nil {
+			t.recorded = map[string]*valueSet{}
+		}
+		if t.recorded[set] == nil {
+			t.recorded[set] = &valueSet{}
+		}
+		t.recorded[set].add(v, "")
+		return llresult.ValidResult(path)
+	})
+}
+
+// IsRecordedIn validates that the value was previously checked by Record for the named set, such
+// as a parent ID referring to the ID of an earlier document.
+func (t *Tracker) IsRecordedIn(set string) IsDef {
+	return Is("recorded in", func(path llpath.Path, v interface{}) *llresult.Results {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		if values := t.recorded[set]; values != nil {
+			if _, seen := values.get(v); seen {
+				return llresult.ValidResult(path)
+			}
+		}
+		return llresult.SimpleResult(path, false, fmt.Sprintf("Value '%v' was not previously recorded in '%s'", v, set))
+	})
+}
+
+// valueSet is a hash set of arbitrary values, each with a tag. Values are hashed by their contents, following
+// pointers, so values that cannot be map keys, such as maps and slices, are supported. Values in a bucket are
+// compared with reflect.DeepEqual.
+type valueSet struct {
+	buckets map[uint64][]taggedValue
+}
+
+type taggedValue struct {
+	value interface{}
+	tag   string
+}
+
+// maxHashDepth limits how deep valueHash looks into a value. Anything deeper only affects which values share a
+// bucket, and stopping there keeps cyclic values from recursing forever.
+const maxHashDepth = 8
+
+// valueHash returns the same hash for values that are reflect.DeepEqual.
+func valueHash(v interface{}) uint64 {
+	h := fnv.New64a()
+	hashValue(h, reflect.ValueOf(v), maxHashDepth)
+	return h.Sum64()
+}
+
+func hashValue(h hash.Hash64, v reflect.Value, depth int) {
+	if !v.IsValid() {
+		h.Write([]byte("nil;"))
+		return
+	}
+	fmt.Fprintf(h, "%s:", v.Type())
+	if depth == 0 {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			hashValue(h, v.Elem(), depth-1)
+		}
+	case reflect.Map:
+		// Entries are hashed separately and summed, so that iteration order does not matter
+		var sum uint64
+		iter := v.MapRange()
+		for iter.Next() {
+			eh := fnv.New64a()
+			hashValue(eh, iter.Key(), depth-1)
+			hashValue(eh, iter.Value(), depth-1)
+			sum += eh.Sum64()
+		}
+		fmt.Fprintf(h, "%d:%d", v.Len(), sum)
+	case reflect.Slice, reflect.Array:
+		fmt.Fprintf(h, "%d:", v.Len())
+		for i := 0; i < v.Len(); i++ {
+			hashValue(h, v.Index(i), depth-1)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			hashValue(h, v.Field(i), depth-1)
+		}
+	case reflect.Float32, reflect.Float64:
+		// 0 and -0 are equal but format differently
+		if f := v.Float(); f != 0 {
+			fmt.Fprint(h, f)
+		}
+	case reflect.Complex64, reflect.Complex128:
+		if c := v.Complex(); c != 0 {
+			fmt.Fprint(h, c)
+		}
+	case reflect.Func:
+		// Functions are only deeply equal when both are nil
+		fmt.Fprint(h, v.IsNil())
+	case reflect.Chan, reflect.UnsafePointer:
+		fmt.Fprint(h, v.Pointer())
+	default:
+		// Use the reflect.Value itself, since Interface is not allowed for unexported struct fields
+		fmt.Fprint(h, v)
+	}
+	h.Write([]byte(";"))
+}
+
+func (s *valueSet) get(v interface{}) (tag string, ok bool) {
+	for _, tv := range s.buckets[valueHash(v)] {
+		if reflect.DeepEqual(tv.value, v) {
+			return tv.tag, true
+		}
+	}
+	return "", false
+}
+
+func (s *valueSet) add(v interface{}, tag string) {
+	if _, ok := s.get(v); ok {
+		return
+	}
+	if s.buckets == nil {
+		s.buckets = map[uint64][]taggedValue{}
+	}
+	h := valueHash(v)
+	s.buckets[h] = append(s.buckets[h], taggedValue{v, tag})
+}
@@ -0,0 +1,141 @@
+package isdef
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackerUniqueUnhashableValues(t *testing.T) {
+	p := llpath.MustParsePath("p")
+	u := NewTracker().IsUniqueTo("")
+
+	assert.True(t, u.Check(p, map[string]interface{}{"a": []int{1}}, true).Valid)
+	assert.True(t, u.Check(p, map[string]interface{}{"a": []int{2}}, true).Valid)
+	assert.False(t, u.Check(p, map[string]interface{}{"a": []int{1}}, true).Valid)
+
+	// Values of different types are never the same value
+	assert.True(t, u.Check(p, 1, true).Valid)
+	assert.True(t, u.Check(p, int64(1), true).Valid)
+	assert.False(t, u.Check(p, 1, true).Valid)
+}
+
+func TestTrackerUniqueNestedPointers(t *testing.T) {
+	type inner struct{ n *int }
+	type outer struct {
+		P    *inner
+		Vals map[string]*int
+	}
+	value := func(n int) outer {
+		x, y := n, n
+		return outer{P: &inner{&x}, Vals: map[string]*int{"a": &y}}
+	}
+
+	p := llpath.MustParsePath("p")
+	u := NewTracker().IsUniqueTo("")
+
+	// Equal values behind different pointers are the same value
+	assert.True(t, u.Check(p, value(1), true).Valid)
+	assert.True(t, u.Check(p, value(2), true).Valid)
+	assert.False(t, u.Check(p, value(1), true).Valid)
+	assert.True(t, u.Check(p, &[]outer{value(2)}, true).Valid)
+	assert.False(t, u.Check(p, &[]outer{value(2)}, true).Valid)
+
+	// Cyclic values are hashed without recursing forever
+	a, b := map[string]interface{}{}, map[string]interface{}{}
+	a["self"], b["self"] = a, b
+	assert.Equal(t, valueHash(a), valueHash(b))
+}
+
+func TestTrackerReset(t *testing.T) {
+	p := llpath.MustParsePath("p")
+	tracker := NewTracker()
+	u := tracker.IsUniqueTo("")
+	first := tracker.IsSameAsFirst("s")
+
+	assert.True(t, u.Check(p, "a", true).Valid)
+	assert.True(t, first.Check(p, "a", true).Valid)
+	tracker.Reset()
+	assert.True(t, u.Check(p, "a", true).Valid)
+	assert.True(t, first.Check(p, "b", true).Valid)
+}
+
+func TestTrackerZeroValue(t *testing.T) {
+	var tracker Tracker
+	p := llpath.MustParsePath("p")
+
+	assert.True(t, tracker.IsUniqueTo("").Check(p, 1, true).Valid)
+	assert.False(t, tracker.IsUniqueTo("").Check(p, 1, true).Valid)
+}
+
+func TestTrackerIsIncreasing(t *testing.T) {
+	p := llpath.MustParsePath("p")
+	tracker := NewTracker()
+	seq := tracker.IsIncreasing("seq")
+	other := tracker.IsIncreasing("other")
+
+	assert.True(t, seq.Check(p, 1, true).Valid)
+	assert.True(t, seq.Check(p, 2, true).Valid)
+	assert.False(t, seq.Check(p, 2, true).Valid)
+	assert.False(t, seq.Check(p, 1, true).Valid)
+	// Series are tracked independently
+	assert.True(t, other.Check(p, 0, true).Valid)
+	assert.True(t, seq.Check(p, 3, true).Valid)
+	assert.False(t, seq.Check(p, "4", true).Valid)
+
+	now := time.Now()
+	ts := tracker.IsNonDecreasing("ts")
+	assert.True(t, ts.Check(p, now, true).Valid)
+	assert.True(t, ts.Check(p, now, true).Valid)
+	assert.False(t, ts.Check(p, now.Add(-time.Second), true).Valid)
+}
+
+func TestTrackerIsSameAsFirst(t *testing.T) {
+	p := llpath.MustParsePath("p")
+	same := NewTracker().IsSameAsFirst("monitor.id")
+
+	assert.True(t, same.Check(p, "abc", true).Valid)
+	assert.True(t, same.Check(p, "abc", true).Valid)
+	assert.False(t, same.Check(p, "def", true).Valid)
+}
+
+func TestTrackerRecord(t *testing.T) {
+	p := llpath.MustParsePath("p")
+	tracker := NewTracker()
+	record := tracker.Record("ids")
+	references := tracker.IsRecordedIn("ids")
+
+	assert.False(t, references.Check(p, "a", true).Valid)
+	assert.True(t, record.Check(p, "a", true).Valid)
+	assert.True(t, references.Check(p, "a", true).Valid)
+	assert.False(t, references.Check(p, "b", true).Valid)
+	assert.False(t, tracker.IsRecordedIn("other").Check(p, "a", true).Valid)
+}
+
+func TestTrackerConcurrentUse(t *testing.T) {
+	p := llpath.MustParsePath("p")
+	u := NewTracker().IsUniqueTo("")
+
+	var wg sync.WaitGroup
+	results := make(chan bool, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results <- u.Check(p, i%50, true).Valid
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+
+	valid := 0
+	for r := range results {
+		if r {
+			valid++
+		}
+	}
+	assert.Equal(t, 50, valid)
+}
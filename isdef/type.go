@@ -0,0 +1,143 @@
+package isdef
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+)
+
+// IsType checks that the given value can be asserted to the type T. If T is an interface type, any value
+// implementing it is valid, so IsType[error]() accepts every error.
+func IsType[T any]() IsDef {
+	return Is(fmt.Sprintf("is a %s", typeNameOf[T]()), func(path llpath.Path, v interface{}) *llresult.Results {
+		if _, ok := v.(T); ok {
+			return llresult.ValidResult(path)
+		}
+		return typeMismatchResult[T](path, v)
+	})
+}
+
+func typeNameOf[T any]() string {
+	return reflect.TypeOf((*T)(nil)).Elem().String()
+}
+
+// typeMismatchResult is the failure recorded when a value cannot be asserted to the type T.
+func typeMismatchResult[T any](path llpath.Path, v interface{}) *llresult.Results {
+	return llresult.SimpleResult(
+		path,
+		false,
+		fmt.Sprintf("Expected a %s, got '%v' which is a %T", typeNameOf[T](), v, v),
+	)
+}
+
+// isKind returns an IsDef checking that the value's reflect.Kind is one of the given kinds.
+func isKind(name string, description string, kinds ...reflect.Kind) IsDef {
+	return Is(name, func(path llpath.Path, v interface{}) *llresult.Results {
+		k := reflect.ValueOf(v).Kind()
+		for _, kind := range kinds {
+			if k == kind {
+				return llresult.ValidResult(path)
+			}
+		}
+		return llresult.SimpleResult(
+			path,
+			false,
+			fmt.Sprintf("Expected %s, got '%v' which is a %T", description, v, v),
+		)
+	})
+}
+
+// IsBool checks that the given value is of a bool kind.
+var IsBool = isKind("is a bool", "a bool", reflect.Bool).withSpec("isBool")
+
+// IsMap checks that the given value is of a map kind, regardless of its key and value types.
+var IsMap = isKind("is a map", "a map", reflect.Map).withSpec("isMap")
+
+// IsSlice checks that the given value is of a slice or array kind, regardless of its element type.
+var IsSlice = isKind("is a slice", "a slice or array", reflect.Slice, reflect.Array).withSpec("isSlice")
+
+// IsNumber checks that the given value is of any integer or floating point kind.
+var IsNumber = isKind(
+	"is a number",
+	"a number",
+	reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+	reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+	reflect.Float32, reflect.Float64,
+).withSpec("isNumber")
+
+// JSONType is one of the value types defined by JSON.
+type JSONType string
+
+// The JSON value types.
+const (
+	JSONObject JSONType = "object"
+	JSONArray  JSONType = "array"
+	JSONString JSONType = "string"
+	JSONNumber JSONType = "number"
+	JSONBool   JSONType = "boolean"
+	JSONNull   JSONType = "null"
+)
+
+// JSONTypeOf returns the JSONType the given value would be encoded as. Pointers are followed, and
+// json.Number is treated as a number. The second return value is false for values, such as structs
+// and funcs, that have no direct JSON equivalent.
+func JSONTypeOf(v interface{}) (JSONType, bool) {
+	if _, ok := v.(json.Number); ok {
+		return JSONNumber, true
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return JSONNull, true
+		}
+		rv = rv.Elem()
+	}
+
+	switch {
+	case !rv.IsValid():
+		return JSONNull, true
+	case rv.Kind() == reflect.Bool:
+		return JSONBool, true
+	case isIntKind(rv.Kind()) || isUintKind(rv.Kind()) || isFloatKind(rv.Kind()):
+		return JSONNumber, true
+	case rv.Kind() == reflect.String:
+		return JSONString, true
+	case rv.Kind() == reflect.Map:
+		if rv.IsNil() {
+			return JSONNull, true
+		}
+		return JSONObject, true
+	case rv.Kind() == reflect.Slice:
+		if rv.IsNil() {
+			return JSONNull, true
+		}
+		return JSONArray, true
+	case rv.Kind() == reflect.Array:
+		return JSONArray, true
+	}
+	return "", false
+}
+
+// IsJSONType checks that the given value would be encoded as one of the given JSON types,
+// e.g. IsJSONType(JSONString, JSONNull) for a nullable string.
+func IsJSONType(types ...JSONType) IsDef {
+	return Is(fmt.Sprintf("is JSON type %v", types), func(path llpath.Path, v interface{}) *llresult.Results {
+		actual, ok := JSONTypeOf(v)
+		if ok {
+			for _, t := range types {
+				if actual == t {
+					return llresult.ValidResult(path)
+				}
+			}
+		}
+		return llresult.SimpleResult(
+			path,
+			false,
+			fmt.Sprintf("Expected JSON type %v, got '%v' which is a %T", types, v, v),
+		)
+	}).withSpec("isJSONType", toArgs(types)...)
+}
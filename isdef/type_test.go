@@ -0,0 +1,86 @@
+package isdef
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsType(t *testing.T) {
+	assertIsDefValid(t, IsType[string](), "foo")
+	assertIsDefInvalid(t, IsType[string](), 1)
+	assertIsDefValid(t, IsType[time.Time](), time.Now())
+	assertIsDefInvalid(t, IsType[time.Time](), &time.Time{})
+	assertIsDefValid(t, IsType[map[string]interface{}](), map[string]interface{}{})
+	assertIsDefInvalid(t, IsType[map[string]interface{}](), map[string]string{})
+
+	// Interface types accept any implementation, but not nil
+	assertIsDefValid(t, IsType[error](), errors.New("boom"))
+	assertIsDefInvalid(t, IsType[error](), nil)
+
+	assert.Equal(t, "is a time.Time", IsType[time.Time]().Name)
+}
+
+func TestKindMatchers(t *testing.T) {
+	assertIsDefValid(t, IsBool, true)
+	assertIsDefInvalid(t, IsBool, "true")
+
+	assertIsDefValid(t, IsMap, map[string]int{})
+	assertIsDefInvalid(t, IsMap, []int{})
+
+	assertIsDefValid(t, IsSlice, []int{})
+	assertIsDefValid(t, IsSlice, [2]int{})
+	assertIsDefInvalid(t, IsSlice, "abc")
+
+	assertIsDefValid(t, IsNumber, 1)
+	assertIsDefValid(t, IsNumber, uint8(1))
+	assertIsDefValid(t, IsNumber, 1.5)
+	assertIsDefInvalid(t, IsNumber, "1")
+	assertIsDefInvalid(t, IsNumber, nil)
+}
+
+func TestJSONTypeOf(t *testing.T) {
+	var nilMap map[string]interface{}
+	var nilPtr *int
+	one := 1
+
+	cases := []struct {
+		value    interface{}
+		expected JSONType
+	}{
+		{nil, JSONNull},
+		{nilMap, JSONNull},
+		{nilPtr, JSONNull},
+		{&one, JSONNumber},
+		{json.Number("1.5"), JSONNumber},
+		{float32(1), JSONNumber},
+		{true, JSONBool},
+		{"s", JSONString},
+		{map[string]int{}, JSONObject},
+		{[]string{}, JSONArray},
+		{[1]int{}, JSONArray},
+	}
+	for _, c := range cases {
+		actual, ok := JSONTypeOf(c.value)
+		assert.True(t, ok)
+		assert.Equal(t, c.expected, actual, "for %#v", c.value)
+	}
+
+	_, ok := JSONTypeOf(struct{}{})
+	assert.False(t, ok)
+}
+
+func TestIsJSONType(t *testing.T) {
+	nullableString := IsJSONType(JSONString, JSONNull)
+
+	assertIsDefValid(t, nullableString, "foo")
+	assertIsDefValid(t, nullableString, nil)
+	assertIsDefInvalid(t, nullableString, 1)
+	assertIsDefInvalid(t, nullableString, struct{}{})
+
+	assertIsDefValid(t, IsJSONType(JSONObject), map[string]interface{}{"a": 1})
+	assertIsDefValid(t, IsJSONType(JSONArray), []interface{}{1})
+}
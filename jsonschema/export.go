@@ -0,0 +1,418 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/elastic/go-lookslike"
+	"github.com/elastic/go-lookslike/isdef"
+	"github.com/elastic/go-lookslike/llpath"
+)
+
+// OpaqueKeyword is the annotation keyword Export uses for checks that have no JSON Schema equivalent, such as
+// custom IsDefs created with isdef.Is. Its value is the IsDef's Name. Compile ignores it, so exported schemas
+// accept any value for those checks.
+const OpaqueKeyword = "x-lookslike-opaque"
+
+// draft is the $schema Export declares.
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+// sliceIndex is the llpath.PathComponentType of slice indices.
+var sliceIndex = llpath.Path{}.ExtendSlice(0)[0].Type
+
+// Export converts a schema definition, as accepted by lookslike.MustCompile, into a JSON Schema document.
+// Top level slices are strict, as they are with MustCompile.
+func Export(definition interface{}) (map[string]interface{}, error) {
+	cs, err := lookslike.CompileSchema(definition)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := ExportSchema(cs)
+	if kind := reflect.ValueOf(definition).Kind(); kind == reflect.Slice || kind == reflect.Array {
+		schema["items"] = false
+	}
+	return schema, nil
+}
+
+// ExportJSON is like Export, but returns the JSON Schema as indented JSON.
+func ExportJSON(definition interface{}) ([]byte, error) {
+	schema, err := Export(definition)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// ExportSchema converts a lookslike.CompiledSchema into a JSON Schema document. Built-in isdef matchers are
+// mapped to the equivalent keywords using their isdef.Spec. Other checks, and validators nested with IsDefs
+// such as isdef.IsSliceOf, are marked with OpaqueKeyword. Keys are required unless their IsDef is Optional,
+// and keys checked with isdef.KeyMissing get the false schema.
+func ExportSchema(cs lookslike.CompiledSchema) map[string]interface{} {
+	root := &node{}
+	cs.Each(func(path llpath.Path, def isdef.IsDef) {
+		root.add(path, def)
+	})
+
+	schema := root.render().(map[string]interface{})
+	schema["$schema"] = draft
+	return schema
+}
+
+// node is a value in the exported document, built up from the paths of a CompiledSchema.
+type node struct {
+	keywords  map[string]interface{}
+	allOf     []interface{}
+	forbidden bool
+
+	properties map[string]*node
+	required   map[string]bool
+
+	items    []*node
+	minItems int
+}
+
+func (n *node) add(path llpath.Path, def isdef.IsDef) {
+	if len(path) == 0 {
+		if def.CheckKeyMissing {
+			n.forbidden = true
+			return
+		}
+		n.merge(defSchema(def))
+		return
+	}
+
+	required := !def.Optional && !def.CheckKeyMissing
+	pc := path[0]
+	var child *node
+	if pc.Type == sliceIndex {
+		for len(n.items) <= pc.Index {
+			n.items = append(n.items, &node{})
+		}
+		child = n.items[pc.Index]
+		if required && pc.Index+1 > n.minItems {
+			n.minItems = pc.Index + 1
+		}
+	} else {
+		if n.properties == nil {
+			n.properties = map[string]*node{}
+			n.required = map[string]bool{}
+		}
+		if child = n.properties[pc.Key]; child == nil {
+			child = &node{}
+			n.properties[pc.Key] = child
+		}
+		if required {
+			n.required[pc.Key] = true
+		}
+	}
+	child.add(path[1:], def)
+}
+
+// merge adds the given keywords, moving any that conflict with existing ones into allOf.
+func (n *node) merge(keywords map[string]interface{}) {
+	if n.keywords == nil {
+		n.keywords = map[string]interface{}{}
+	}
+	for k, v := range keywords {
+		if existing, ok := n.keywords[k]; ok && !reflect.DeepEqual(existing, v) {
+			n.allOf = append(n.allOf, keywords)
+			return
+		}
+	}
+	for k, v := range keywords {
+		n.keywords[k] = v
+	}
+}
+
+func (n *node) render() interface{} {
+	if n.forbidden {
+		return false
+	}
+
+	out := map[string]interface{}{}
+	for k, v := range n.keywords {
+		out[k] = v
+	}
+	if len(n.allOf) > 0 {
+		out["allOf"] = n.allOf
+	}
+
+	if n.properties != nil {
+		out["type"] = "object"
+		props := map[string]interface{}{}
+		for k, child := range n.properties {
+			props[k] = child.render()
+		}
+		out["properties"] = props
+
+		if len(n.required) > 0 {
+			required := make([]string, 0, len(n.required))
+			for k := range n.required {
+				required = append(required, k)
+			}
+			sort.Strings(required)
+			out["required"] = required
+		}
+	}
+
+	if n.items != nil {
+		out["type"] = "array"
+		items := make([]interface{}, len(n.items))
+		for i, child := range n.items {
+			items[i] = child.render()
+		}
+		out["prefixItems"] = items
+		if n.minItems > 0 {
+			out["minItems"] = n.minItems
+		}
+	}
+
+	return out
+}
+
+// defSchema returns the JSON Schema keywords for a single IsDef.
+func defSchema(def isdef.IsDef) map[string]interface{} {
+	if def.Spec == nil {
+		if def.Checker == nil && def.DocumentChecker == nil {
+			return map[string]interface{}{}
+		}
+		return opaque(def)
+	}
+
+	args := def.Spec.Args
+	switch def.Spec.Matcher {
+	case "keyPresent":
+		return map[string]interface{}{}
+	case "keyMissing":
+		return map[string]interface{}{"not": map[string]interface{}{}}
+	case "isEqual", "isDeepEqual":
+		if v, ok := jsonValue(args[0]); ok {
+			return map[string]interface{}{"const": v}
+		}
+	case "isNil":
+		return typed("null")
+	case "isString", "isValidUTF8String":
+		return typed("string")
+	case "isNonEmptyString":
+		return typed("string", "minLength", 1)
+	case "isStringMatching":
+		return typed("string", "pattern", args[0].(*regexp.Regexp).String())
+	case "isStringContaining":
+		return typed("string", "pattern", regexp.QuoteMeta(args[0].(string)))
+	case "isStringWithPrefix":
+		return typed("string", "pattern", "^"+regexp.QuoteMeta(args[0].(string)))
+	case "isStringWithSuffix":
+		return typed("string", "pattern", regexp.QuoteMeta(args[0].(string))+"$")
+	case "isStringOfLength":
+		return typed("string", "minLength", args[0], "maxLength", args[0])
+	case "isStringLengthBetween":
+		return withMax(typed("string", "minLength", args[0]), "maxLength", args[1].(int))
+	case "isStringOneOf":
+		return typed("string", "enum", args)
+	case "isUUID":
+		return typed("string", "format", "uuid")
+	case "isEmail":
+		return typed("string", "format", "email")
+	case "isHostname":
+		return typed("string", "format", "hostname")
+	case "isURL":
+		s := typed("string", "format", "uri")
+		if len(args) > 0 {
+			s[OpaqueKeyword] = def.Name
+		}
+		return s
+	case "isIP":
+		s := typed("string")
+		switch {
+		case len(args) == 1 && isConstraint(args[0], isdef.IPv4):
+			s["format"] = "ipv4"
+		case len(args) == 1 && isConstraint(args[0], isdef.IPv6):
+			s["format"] = "ipv6"
+		default:
+			s["anyOf"] = []interface{}{
+				map[string]interface{}{"format": "ipv4"},
+				map[string]interface{}{"format": "ipv6"},
+			}
+			if len(args) > 0 {
+				s[OpaqueKeyword] = def.Name
+			}
+		}
+		return s
+	case "isHex":
+		if length := args[0].(int); length > 0 {
+			return typed("string", "pattern", fmt.Sprintf("^[0-9a-fA-F]{%d}$", length))
+		}
+		return typed("string", "pattern", "^([0-9a-fA-F]{2})*$")
+	case "isPort":
+		return typed("integer", "minimum", 0, "maximum", 65535)
+	case "isIntGt":
+		return typed("integer", "exclusiveMinimum", args[0])
+	case "isGt", "isGte", "isLt", "isLte":
+		if _, ok := toFloat(args[0]); ok {
+			keyword := map[string]string{
+				"isGt": "exclusiveMinimum", "isGte": "minimum", "isLt": "exclusiveMaximum", "isLte": "maximum",
+			}[def.Spec.Matcher]
+			return typed("number", keyword, args[0])
+		}
+	case "isBool":
+		return typed("boolean")
+	case "isNumber":
+		return typed("number")
+	case "isMap":
+		return typed("object")
+	case "isSlice":
+		return typed("array")
+	case "isJSONType":
+		types := make([]interface{}, len(args))
+		for i, t := range args {
+			types[i] = string(t.(isdef.JSONType))
+		}
+		if len(types) == 1 {
+			return map[string]interface{}{"type": types[0]}
+		}
+		return map[string]interface{}{"type": types}
+	case "isNonEmptySlice":
+		return typed("array", "minItems", 1)
+	case "isSliceOfLength":
+		return typed("array", "minItems", args[0], "maxItems", args[0])
+	case "isSliceLengthBetween":
+		return withMax(typed("array", "minItems", args[0]), "maxItems", args[1].(int))
+	case "isSliceOf":
+		return typed("array", "items", nestedValidator(def))
+	case "isSliceContaining":
+		return typed("array", "contains", nestedValidator(def))
+	case "isMapWithKeys":
+		return typed("object", "required", args)
+	case "isMapWithExactKeys":
+		props := map[string]interface{}{}
+		for _, k := range args {
+			props[k.(string)] = true
+		}
+		return typed("object", "required", args, "properties", props, "additionalProperties", false)
+	case "isMapKeysMatching":
+		pattern := args[0].(*regexp.Regexp).String()
+		return typed("object", "propertyNames", map[string]interface{}{"pattern": pattern})
+	case "isMapLengthBetween":
+		return withMax(typed("object", "minProperties", args[0]), "maxProperties", args[1].(int))
+	case "isMapOf":
+		return typed("object", "additionalProperties", nestedValidator(def))
+	case "isSchema":
+		return nestedValidator(def)
+	case "isAny":
+		return map[string]interface{}{"anyOf": defSchemas(args)}
+	case "all":
+		return map[string]interface{}{"allOf": defSchemas(args)}
+	case "exactlyOne":
+		return map[string]interface{}{"oneOf": defSchemas(args)}
+	case "not":
+		return map[string]interface{}{"not": defSchema(args[0].(isdef.IsDef))}
+	case "sensitive":
+		return defSchema(args[0].(isdef.IsDef))
+	}
+	return opaque(def)
+}
+
+// isConstraint compares IPConstraints by name, as they cannot be compared with ==.
+func isConstraint(arg interface{}, c isdef.IPConstraint) bool {
+	return arg.(isdef.IPConstraint).String() == c.String()
+}
+
+func defSchemas(args []interface{}) []interface{} {
+	schemas := make([]interface{}, len(args))
+	for i, arg := range args {
+		schemas[i] = defSchema(arg.(isdef.IsDef))
+	}
+	return schemas
+}
+
+func opaque(def isdef.IsDef) map[string]interface{} {
+	return map[string]interface{}{OpaqueKeyword: def.Name}
+}
+
+// nestedValidator describes a validator.Validator given to an IsDef, which cannot be inspected.
+func nestedValidator(def isdef.IsDef) map[string]interface{} {
+	return map[string]interface{}{OpaqueKeyword: def.Name + " validator"}
+}
+
+// typed returns a schema with the given type and keyword/value pairs.
+func typed(jsonType string, keywordValues ...interface{}) map[string]interface{} {
+	s := map[string]interface{}{"type": jsonType}
+	for i := 0; i < len(keywordValues); i += 2 {
+		s[keywordValues[i].(string)] = keywordValues[i+1]
+	}
+	return s
+}
+
+// withMax sets the given keyword unless max is negative, which means unbounded for isdef matchers.
+func withMax(s map[string]interface{}, keyword string, max int) map[string]interface{} {
+	if max >= 0 {
+		s[keyword] = max
+	}
+	return s
+}
+
+// jsonValue returns v if it is made up only of values with a direct JSON equivalent.
+func jsonValue(v interface{}) (interface{}, bool) {
+	if v == nil {
+		return nil, true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		// Named types, such as time.Duration, usually mean something other than their JSON value
+		if rv.Type().PkgPath() != "" {
+			return nil, false
+		}
+		return v, true
+	case reflect.Slice, reflect.Array:
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			elem, ok := jsonValue(rv.Index(i).Interface())
+			if !ok {
+				return nil, false
+			}
+			out[i] = elem
+		}
+		return out, true
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		out := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			elem, ok := jsonValue(iter.Value().Interface())
+			if !ok {
+				return nil, false
+			}
+			out[iter.Key().String()] = elem
+		}
+		return out, true
+	}
+	return nil, false
+}
@@ -0,0 +1,163 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-lookslike/isdef"
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var isEven = isdef.Is("is even", func(path llpath.Path, v interface{}) *llresult.Results {
+	return llresult.SimpleResult(path, v.(int)%2 == 0, "Value %v is not even", v)
+})
+
+func monitorDefinition() map[string]interface{} {
+	return map[string]interface{}{
+		"monitor": map[string]interface{}{
+			"id":     isdef.IsStringMatching(regexp.MustCompile(`^[a-z0-9-]+$`)),
+			"status": isdef.IsStringOneOf("up", "down"),
+			"ip":     isdef.Optional(isdef.IsIP(isdef.IPv4)),
+			"count":  isEven,
+		},
+		"tags":     []interface{}{"first", isdef.IsNonEmptyString},
+		"duration": time.Second,
+		"error":    isdef.KeyMissing,
+		"port":     isdef.IsAny(isdef.IsPort, isdef.IsNil),
+		"version":  1,
+	}
+}
+
+func TestExport(t *testing.T) {
+	schema, err := Export(monitorDefinition())
+	require.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"$schema":  draft,
+		"type":     "object",
+		"required": []string{"duration", "monitor", "port", "tags", "version"},
+		"properties": map[string]interface{}{
+			"monitor": map[string]interface{}{
+				"type":     "object",
+				"required": []string{"count", "id", "status"},
+				"properties": map[string]interface{}{
+					"id":     map[string]interface{}{"type": "string", "pattern": `^[a-z0-9-]+$`},
+					"status": map[string]interface{}{"type": "string", "enum": []interface{}{"up", "down"}},
+					"ip":     map[string]interface{}{"type": "string", "format": "ipv4"},
+					"count":  map[string]interface{}{OpaqueKeyword: "is even"},
+				},
+			},
+			"tags": map[string]interface{}{
+				"type":     "array",
+				"minItems": 2,
+				"prefixItems": []interface{}{
+					map[string]interface{}{"const": "first"},
+					map[string]interface{}{"type": "string", "minLength": 1},
+				},
+			},
+			"duration": map[string]interface{}{OpaqueKeyword: "equals"},
+			"error":    false,
+			"port": map[string]interface{}{"anyOf": []interface{}{
+				map[string]interface{}{"type": "integer", "minimum": 0, "maximum": 65535},
+				map[string]interface{}{"type": "null"},
+			}},
+			"version": map[string]interface{}{"const": 1},
+		},
+	}
+	assert.Equal(t, expected, schema)
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	out, err := ExportJSON(monitorDefinition())
+	require.NoError(t, err)
+
+	v, err := Compile(out)
+	require.NoError(t, err)
+
+	doc := map[string]interface{}{
+		"monitor":  map[string]interface{}{"id": "abc", "status": "up", "count": 3},
+		"tags":     []interface{}{"first", "second"},
+		"duration": 1000,
+		"port":     nil,
+		"version":  1,
+	}
+	res := v(doc)
+	assert.True(t, res.Valid, "%v", res.Errors())
+
+	doc["error"] = "boom"
+	doc["monitor"].(map[string]interface{})["status"] = "flapping"
+	res = v(doc)
+	assert.False(t, res.Valid)
+	assert.False(t, res.Fields["error"][0].Valid)
+	assert.False(t, res.Fields["monitor.status"][0].Valid)
+}
+
+func TestExportRoundTripContainsAndPropertyNames(t *testing.T) {
+	out, err := ExportJSON(map[string]interface{}{
+		"tags":   isdef.IsSliceContaining(func(interface{}) *llresult.Results { return llresult.NewResults() }),
+		"labels": isdef.IsMapKeysMatching(regexp.MustCompile(`^[a-z]+$`)),
+	})
+	require.NoError(t, err)
+
+	v, err := Compile(out)
+	require.NoError(t, err)
+
+	doc := map[string]interface{}{
+		"tags":   []interface{}{"a"},
+		"labels": map[string]interface{}{"env": "prod"},
+	}
+	assert.True(t, v(doc).Valid)
+
+	// The contained validator is opaque, but contains still needs an element
+	doc["tags"] = []interface{}{}
+	doc["labels"] = map[string]interface{}{"Env": "prod"}
+	res := v(doc)
+	assert.False(t, res.Valid)
+	assert.False(t, res.Fields["tags"][0].Valid)
+	assert.False(t, res.Fields["labels.Env"][0].Valid)
+}
+
+func TestExportTopLevel(t *testing.T) {
+	schema, err := Export([]interface{}{1, isdef.IsSliceOf(nil)})
+	require.NoError(t, err)
+	assert.Equal(t, false, schema["items"])
+	assert.Equal(t, map[string]interface{}{
+		"type":  "array",
+		"items": map[string]interface{}{OpaqueKeyword: "slice validator"},
+	}, schema["prefixItems"].([]interface{})[1])
+
+	schema, err = Export(isdef.All(isdef.IsMapWithExactKeys("a"), isdef.Not(isdef.IsMapLengthBetween(2, -1))))
+	require.NoError(t, err)
+	out, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"allOf": [
+			{"type": "object", "required": ["a"], "properties": {"a": true}, "additionalProperties": false},
+			{"not": {"type": "object", "minProperties": 2}}
+		]
+	}`, string(out))
+}
@@ -0,0 +1,664 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+/*
+Package jsonschema converts between JSON Schema (draft 2020-12) documents and lookslike validators.
+
+Compile turns a JSON Schema into a validator.Validator built from isdef matchers. Only the subset of
+keywords listed on Compile is supported, and any other keyword is reported as a compile error rather than
+being silently ignored.
+
+Export does the reverse, describing a schema definition as JSON Schema so it can be shared with tools
+outside of Go.
+*/
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-lookslike/internal/llreflect"
+	"github.com/elastic/go-lookslike/isdef"
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+	"github.com/elastic/go-lookslike/validator"
+)
+
+// CompileError describes a problem with a single keyword of a JSON Schema.
+type CompileError struct {
+	// Location is the JSON pointer of the keyword within the schema, such as "#/properties/id/pattern".
+	Location string
+	Msg      string
+}
+
+func (e CompileError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Location, e.Msg)
+}
+
+// CompileErrors is returned by Compile with every problem found in a schema.
+type CompileErrors []CompileError
+
+func (es CompileErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return fmt.Sprintf("invalid JSON Schema: %s", strings.Join(msgs, "; "))
+}
+
+// annotations are keywords that do not affect validation.
+var annotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "$anchor": true,
+	"title": true, "description": true, "default": true, "examples": true,
+	"deprecated": true, "readOnly": true, "writeOnly": true, "format": true,
+	"$defs": true, "definitions": true,
+}
+
+// Compile converts a JSON Schema document into a validator.Validator. The supported keywords are
+// type, enum, const, properties, required, additionalProperties, minProperties, maxProperties, pattern,
+// minLength, maxLength, minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf, items, minItems,
+// maxItems, uniqueItems, prefixItems, contains, propertyNames, allOf, anyOf, oneOf, not and local $refs such
+// as "#/$defs/address".
+// Annotations such as title, description and format, and extension keywords starting with "x-", are ignored.
+// Any other keyword is a compile error.
+//
+// As in JSON Schema, keywords only apply to values of the matching type, so minLength accepts numbers
+// unless type is also given. Numbers of any Go numeric type are accepted and compared by value.
+func Compile(schema []byte) (validator.Validator, error) {
+	var doc interface{}
+	if err := json.Unmarshal(schema, &doc); err != nil {
+		return nil, err
+	}
+	return CompileValue(doc)
+}
+
+// CompileValue is like Compile, but takes a schema that has already been decoded with encoding/json.
+func CompileValue(schema interface{}) (validator.Validator, error) {
+	c := &compiler{root: schema, refs: map[string]*isdef.IsDef{}}
+	def := c.compile("#", schema)
+	if len(c.errs) > 0 {
+		sort.Slice(c.errs, func(i, j int) bool { return c.errs[i].Location < c.errs[j].Location })
+		return nil, c.errs
+	}
+
+	return defValidator(def), nil
+}
+
+// defValidator adapts an IsDef for use where a validator.Validator is required.
+func defValidator(def isdef.IsDef) validator.Validator {
+	return func(actual interface{}) *llresult.Results {
+		return def.Check(llpath.Path{}, actual, true)
+	}
+}
+
+// MustCompile is the panic-ing equivalent of Compile.
+func MustCompile(schema []byte) validator.Validator {
+	v, err := Compile(schema)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+type compiler struct {
+	root interface{}
+	// refs holds the IsDefs compiled for each $ref target, filled in after compiling so recursive schemas work
+	refs map[string]*isdef.IsDef
+	errs CompileErrors
+}
+
+func (c *compiler) fail(loc string, msg string, args ...interface{}) {
+	c.errs = append(c.errs, CompileError{loc, fmt.Sprintf(msg, args...)})
+}
+
+func (c *compiler) compile(loc string, schema interface{}) isdef.IsDef {
+	switch s := schema.(type) {
+	case bool:
+		if s {
+			return isdef.KeyPresent
+		}
+		return isdef.Is("false schema", func(path llpath.Path, v interface{}) *llresult.Results {
+			return llresult.SimpleResult(path, false, "No value is valid for the false schema")
+		})
+	case map[string]interface{}:
+		return c.compileObject(loc, s)
+	default:
+		c.fail(loc, "schema must be an object or a boolean, got %T", schema)
+		return isdef.KeyPresent
+	}
+}
+
+func (c *compiler) compileObject(loc string, s map[string]interface{}) isdef.IsDef {
+	keywords := make([]string, 0, len(s))
+	for k := range s {
+		keywords = append(keywords, k)
+	}
+	sort.Strings(keywords)
+
+	var defs []isdef.IsDef
+	handledObject, handledArray := false, false
+	for _, k := range keywords {
+		kLoc := loc + "/" + k
+		v := s[k]
+		switch k {
+		case "type":
+			defs = append(defs, c.compileType(kLoc, v))
+		case "enum":
+			values, ok := v.([]interface{})
+			if !ok {
+				c.fail(kLoc, "enum must be an array")
+				continue
+			}
+			defs = append(defs, isEnum(values))
+		case "const":
+			defs = append(defs, isEnum([]interface{}{v}))
+		case "properties", "required", "additionalProperties":
+			if !handledObject {
+				defs = append(defs, c.compileProperties(loc, s))
+				handledObject = true
+			}
+		case "minProperties", "maxProperties":
+			if min, max, ok := c.bounds(loc, s, "minProperties", "maxProperties", k); ok {
+				defs = append(defs, forJSONType(isdef.JSONObject, isdef.IsMapLengthBetween(min, max)))
+			}
+		case "pattern":
+			pattern, ok := v.(string)
+			if !ok {
+				c.fail(kLoc, "pattern must be a string")
+				continue
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				c.fail(kLoc, "invalid pattern: %s", err)
+				continue
+			}
+			defs = append(defs, forJSONType(isdef.JSONString, isdef.IsStringMatching(re)))
+		case "minLength", "maxLength":
+			if min, max, ok := c.bounds(loc, s, "minLength", "maxLength", k); ok {
+				defs = append(defs, forJSONType(isdef.JSONString, isdef.IsStringLengthBetween(min, max)))
+			}
+		case "minItems", "maxItems":
+			if min, max, ok := c.bounds(loc, s, "minItems", "maxItems", k); ok {
+				defs = append(defs, forJSONType(isdef.JSONArray, isdef.IsSliceLengthBetween(min, max)))
+			}
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf":
+			n, ok := v.(float64)
+			if !ok {
+				c.fail(kLoc, "%s must be a number", k)
+				continue
+			}
+			if k == "multipleOf" && n <= 0 {
+				c.fail(kLoc, "multipleOf must be greater than 0")
+				continue
+			}
+			defs = append(defs, forJSONType(isdef.JSONNumber, numberCheck(k, n)))
+		case "uniqueItems":
+			unique, ok := v.(bool)
+			if !ok {
+				c.fail(kLoc, "uniqueItems must be a boolean")
+			} else if unique {
+				defs = append(defs, forJSONType(isdef.JSONArray, uniqueItems))
+			}
+		case "items", "prefixItems":
+			if !handledArray {
+				defs = append(defs, c.compileItems(loc, s))
+				handledArray = true
+			}
+		case "contains":
+			defs = append(defs, forJSONType(isdef.JSONArray, isdef.IsSliceContaining(defValidator(c.compile(kLoc, v)))))
+		case "propertyNames":
+			defs = append(defs, forJSONType(isdef.JSONObject, propertyNames(c.compile(kLoc, v))))
+		case "allOf", "anyOf", "oneOf":
+			subs, ok := v.([]interface{})
+			if !ok || len(subs) == 0 {
+				c.fail(kLoc, "%s must be a non-empty array", k)
+				continue
+			}
+			subDefs := make([]isdef.IsDef, len(subs))
+			for i, sub := range subs {
+				subDefs[i] = c.compile(fmt.Sprintf("%s/%d", kLoc, i), sub)
+			}
+			switch k {
+			case "allOf":
+				defs = append(defs, isdef.All(subDefs...))
+			case "anyOf":
+				defs = append(defs, isdef.IsAny(subDefs...))
+			case "oneOf":
+				defs = append(defs, isdef.ExactlyOne(subDefs...))
+			}
+		case "not":
+			defs = append(defs, isdef.Not(c.compile(kLoc, v)))
+		case "$ref":
+			ref, ok := v.(string)
+			if !ok {
+				c.fail(kLoc, "$ref must be a string")
+				continue
+			}
+			defs = append(defs, c.compileRef(kLoc, ref))
+		default:
+			if !annotations[k] && !strings.HasPrefix(k, "x-") {
+				c.fail(kLoc, "unsupported keyword '%s'", k)
+			}
+		}
+	}
+
+	if len(defs) == 1 {
+		return defs[0]
+	}
+	return isdef.All(defs...)
+}
+
+// bounds reads a pair of min/max keywords. It only returns ok for the keyword named by current,
+// or for the min keyword when both are present, so each pair produces a single IsDef.
+func (c *compiler) bounds(loc string, s map[string]interface{}, minKey, maxKey, current string) (min, max int, ok bool) {
+	_, hasMin := s[minKey]
+	if current == maxKey && hasMin {
+		return 0, 0, false
+	}
+
+	min, max = 0, -1
+	ok = true
+	for key, target := range map[string]*int{minKey: &min, maxKey: &max} {
+		raw, present := s[key]
+		if !present {
+			continue
+		}
+		n, isNum := raw.(float64)
+		if !isNum || n < 0 || n != math.Trunc(n) {
+			c.fail(loc+"/"+key, "%s must be a non-negative integer", key)
+			ok = false
+			continue
+		}
+		*target = int(n)
+	}
+	return min, max, ok
+}
+
+func (c *compiler) compileType(loc string, v interface{}) isdef.IsDef {
+	var names []string
+	switch t := v.(type) {
+	case string:
+		names = []string{t}
+	case []interface{}:
+		for _, n := range t {
+			s, ok := n.(string)
+			if !ok {
+				c.fail(loc, "type must be a string or an array of strings")
+				return isdef.KeyPresent
+			}
+			names = append(names, s)
+		}
+	default:
+		c.fail(loc, "type must be a string or an array of strings")
+		return isdef.KeyPresent
+	}
+
+	var jsonTypes []isdef.JSONType
+	allowInteger := false
+	for _, n := range names {
+		switch n {
+		case "object", "array", "string", "number", "boolean", "null":
+			jsonTypes = append(jsonTypes, isdef.JSONType(n))
+		case "integer":
+			allowInteger = true
+		default:
+			c.fail(loc, "unknown type '%s'", n)
+		}
+	}
+
+	isType := isdef.IsJSONType(jsonTypes...)
+	return isdef.Is(fmt.Sprintf("is JSON type %v", names), func(path llpath.Path, v interface{}) *llresult.Results {
+		if allowInteger {
+			if f, ok := toFloat(v); ok && f == math.Trunc(f) {
+				return llresult.ValidResult(path)
+			}
+		}
+		if len(jsonTypes) > 0 && isType.Check(path, v, true).Valid {
+			return llresult.ValidResult(path)
+		}
+		return llresult.SimpleResult(path, false, fmt.Sprintf("Expected JSON type %v, got '%v' which is a %T", names, v, v))
+	})
+}
+
+func (c *compiler) compileProperties(loc string, s map[string]interface{}) isdef.IsDef {
+	props := map[string]isdef.IsDef{}
+	if raw, ok := s["properties"]; ok {
+		propSchemas, ok := raw.(map[string]interface{})
+		if !ok {
+			c.fail(loc+"/properties", "properties must be an object")
+		}
+		for name, propSchema := range propSchemas {
+			props[name] = c.compile(loc+"/properties/"+escapePointer(name), propSchema)
+		}
+	}
+
+	required := map[string]bool{}
+	if raw, ok := s["required"]; ok {
+		names, ok := raw.([]interface{})
+		if !ok {
+			c.fail(loc+"/required", "required must be an array of strings")
+		}
+		for _, n := range names {
+			name, ok := n.(string)
+			if !ok {
+				c.fail(loc+"/required", "required must be an array of strings")
+				continue
+			}
+			required[name] = true
+		}
+	}
+
+	var additional *isdef.IsDef
+	if raw, ok := s["additionalProperties"]; ok {
+		def := c.compile(loc+"/additionalProperties", raw)
+		if raw == false {
+			// Reported the same way as unexpected fields are by lookslike.Strict
+			def = isdef.Is("no additional properties", func(path llpath.Path, v interface{}) *llresult.Results {
+				return llresult.StrictFailureResult(path)
+			})
+		}
+		additional = &def
+	}
+
+	names := make([]string, 0, len(props)+len(required))
+	for name := range props {
+		names = append(names, name)
+	}
+	for name := range required {
+		if _, ok := props[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return forJSONType(isdef.JSONObject, isdef.Is("object properties", func(path llpath.Path, v interface{}) *llresult.Results {
+		res := llresult.ValidResult(path)
+		entries := mapEntries(v)
+
+		for _, name := range names {
+			value, exists := entries[name]
+			if !exists {
+				// Checked here rather than by the property's IsDef, which may accept a missing key, e.g. for not
+				if required[name] {
+					res.Merge(llresult.KeyMissingResult(path.ExtendMap(name)))
+				}
+				continue
+			}
+			def, ok := props[name]
+			if !ok {
+				def = isdef.KeyPresent
+			}
+			res.Merge(def.Check(path.ExtendMap(name), value, true))
+		}
+
+		if additional != nil {
+			for key, value := range entries {
+				if _, ok := props[key]; !ok {
+					res.Merge(additional.Check(path.ExtendMap(key), value, true))
+				}
+			}
+		}
+		return res
+	}))
+}
+
+func (c *compiler) compileItems(loc string, s map[string]interface{}) isdef.IsDef {
+	var prefix []isdef.IsDef
+	if raw, ok := s["prefixItems"]; ok {
+		schemas, ok := raw.([]interface{})
+		if !ok {
+			c.fail(loc+"/prefixItems", "prefixItems must be an array")
+		}
+		for i, schema := range schemas {
+			prefix = append(prefix, c.compile(fmt.Sprintf("%s/prefixItems/%d", loc, i), schema))
+		}
+	}
+
+	var items *isdef.IsDef
+	if raw, ok := s["items"]; ok {
+		def := c.compile(loc+"/items", raw)
+		items = &def
+	}
+
+	// Missing prefix items are allowed, as they are in JSON Schema, and items only applies after the prefix
+	return forJSONType(isdef.JSONArray, isdef.Is("array items", func(path llpath.Path, v interface{}) *llresult.Results {
+		rv := reflect.ValueOf(v)
+		res := llresult.ValidResult(path)
+		for i := 0; i < rv.Len(); i++ {
+			def := items
+			if i < len(prefix) {
+				def = &prefix[i]
+			}
+			if def != nil {
+				res.Merge(def.Check(path.ExtendSlice(i), rv.Index(i).Interface(), true))
+			}
+		}
+		return res
+	}))
+}
+
+// propertyNames checks each key of an object with def, recording the results under the key.
+func propertyNames(def isdef.IsDef) isdef.IsDef {
+	return isdef.Is("property names", func(path llpath.Path, v interface{}) *llresult.Results {
+		var keys []string
+		for _, k := range reflect.ValueOf(v).MapKeys() {
+			keys = append(keys, fmt.Sprint(k.Interface()))
+		}
+		sort.Strings(keys)
+
+		res := llresult.ValidResult(path)
+		for _, k := range keys {
+			res.Merge(def.Check(path.ExtendMap(k), k, true))
+		}
+		return res
+	})
+}
+
+func (c *compiler) compileRef(loc string, ref string) isdef.IsDef {
+	if !strings.HasPrefix(ref, "#") {
+		c.fail(loc, "only local $refs starting with '#' are supported, got '%s'", ref)
+		return isdef.KeyPresent
+	}
+
+	if _, seen := c.refs[ref]; !seen {
+		target, err := resolvePointer(c.root, ref)
+		if err != nil {
+			c.fail(loc, "%s", err)
+			return isdef.KeyPresent
+		}
+
+		// Register before compiling, so references back to this target find it
+		def := &isdef.IsDef{}
+		c.refs[ref] = def
+		*def = c.compile(ref, target)
+	}
+
+	def := c.refs[ref]
+	return isdef.Is("$ref "+ref, func(path llpath.Path, v interface{}) *llresult.Results {
+		return def.Check(path, v, true)
+	})
+}
+
+// resolvePointer resolves a JSON pointer fragment such as "#/$defs/address" within doc.
+func resolvePointer(doc interface{}, ref string) (interface{}, error) {
+	pointer := strings.TrimPrefix(ref, "#")
+	if pointer == "" {
+		return doc, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("unsupported $ref '%s', only JSON pointers are supported", ref)
+	}
+
+	current := doc
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("$ref '%s' does not resolve to a schema", ref)
+		}
+		if current, ok = m[token]; !ok {
+			return nil, fmt.Errorf("$ref '%s' does not resolve to a schema", ref)
+		}
+	}
+	return current, nil
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+// forJSONType applies def only to values of the given JSON type, as JSON Schema keywords do. Pointers are
+// followed, as they are by isdef.JSONTypeOf, so def is given the value pointed to.
+func forJSONType(t isdef.JSONType, def isdef.IsDef) isdef.IsDef {
+	return isdef.Is(def.Name, func(path llpath.Path, v interface{}) *llresult.Results {
+		if actual, _ := isdef.JSONTypeOf(v); actual != t {
+			return llresult.ValidResult(path)
+		}
+		return def.Check(path, llreflect.ChaseValue(reflect.ValueOf(v)).Interface(), true)
+	})
+}
+
+func isEnum(values []interface{}) isdef.IsDef {
+	return isdef.Is("is one of enum", func(path llpath.Path, v interface{}) *llresult.Results {
+		for _, allowed := range values {
+			if jsonEqual(v, allowed) {
+				return llresult.ValidResult(path)
+			}
+		}
+		return llresult.SimpleResult(path, false, fmt.Sprintf("Value %#v is not one of %v", v, values))
+	})
+}
+
+func numberCheck(keyword string, limit float64) isdef.IsDef {
+	return isdef.Is(keyword, func(path llpath.Path, v interface{}) *llresult.Results {
+		n, _ := toFloat(v)
+		var valid bool
+		var expected string
+		switch keyword {
+		case "minimum":
+			valid, expected = n >= limit, ">="
+		case "maximum":
+			valid, expected = n <= limit, "<="
+		case "exclusiveMinimum":
+			valid, expected = n > limit, ">"
+		case "exclusiveMaximum":
+			valid, expected = n < limit, "<"
+		case "multipleOf":
+			valid, expected = isMultipleOf(n, limit), "a multiple of"
+		}
+		if !valid {
+			return llresult.SimpleResult(path, false, fmt.Sprintf("%v is not %s %v", v, expected, limit))
+		}
+		return llresult.ValidResult(path)
+	})
+}
+
+// isMultipleOf divides the shortest decimal forms of n and divisor exactly, so that 0.3 is a multiple of 0.1
+// even though the quotient of their float64 values is not a whole number.
+func isMultipleOf(n, divisor float64) bool {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return false
+	}
+	nr, _ := new(big.Rat).SetString(strconv.FormatFloat(n, 'g', -1, 64))
+	dr, _ := new(big.Rat).SetString(strconv.FormatFloat(divisor, 'g', -1, 64))
+	return nr.Quo(nr, dr).IsInt()
+}
+
+var uniqueItems = isdef.Is("unique items", func(path llpath.Path, v interface{}) *llresult.Results {
+	rv := reflect.ValueOf(v)
+	res := llresult.ValidResult(path)
+	for i := 0; i < rv.Len(); i++ {
+		for j := 0; j < i; j++ {
+			if jsonEqual(rv.Index(i).Interface(), rv.Index(j).Interface()) {
+				res.Record(path.ExtendSlice(i), llresult.ValueResult{
+					Valid:   false,
+					Message: fmt.Sprintf("Item is a duplicate of item %d", j),
+				})
+				break
+			}
+		}
+	}
+	return res
+})
+
+// mapEntries returns the entries of a map with string keys.
+func mapEntries(v interface{}) map[string]interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m
+	}
+
+	rv := reflect.ValueOf(v)
+	out := make(map[string]interface{}, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		out[fmt.Sprint(iter.Key().Interface())] = iter.Value().Interface()
+	}
+	return out
+}
+
+// jsonEqual compares two values as JSON would, treating all numbers as float64.
+func jsonEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func normalize(v interface{}) interface{} {
+	if f, ok := toFloat(v); ok {
+		return f
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		out := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = normalize(iter.Value().Interface())
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			out[i] = normalize(rv.Index(i).Interface())
+		}
+		return out
+	}
+	return v
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
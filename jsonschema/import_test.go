@@ -0,0 +1,309 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const monitorSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"title": "monitor event",
+	"type": "object",
+	"required": ["id", "status", "duration"],
+	"additionalProperties": false,
+	"properties": {
+		"id": {"type": "string", "pattern": "^[a-z0-9-]+$", "minLength": 3, "maxLength": 16},
+		"status": {"enum": ["up", "down"]},
+		"duration": {"type": "integer", "minimum": 0, "exclusiveMaximum": 60000},
+		"ratio": {"type": "number", "multipleOf": 0.25},
+		"tags": {"type": "array", "items": {"type": "string"}, "minItems": 1, "uniqueItems": true},
+		"host": {"$ref": "#/$defs/host"}
+	},
+	"$defs": {
+		"host": {
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string"},
+				"ip": {"anyOf": [{"type": "string"}, {"type": "null"}]}
+			}
+		}
+	}
+}`
+
+func validMonitor() map[string]interface{} {
+	return map[string]interface{}{
+		"id":       "my-monitor",
+		"status":   "up",
+		"duration": 1500,
+		"ratio":    0.75,
+		"tags":     []string{"a", "b"},
+		"host":     map[string]interface{}{"name": "web-1", "ip": nil, "extra": true},
+	}
+}
+
+func hasInvalid(res *llresult.Results, path string) bool {
+	for _, vr := range res.Fields[path] {
+		if !vr.Valid {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCompile(t *testing.T) {
+	v, err := Compile([]byte(monitorSchema))
+	require.NoError(t, err)
+
+	res := v(validMonitor())
+	assert.True(t, res.Valid, "%v", res.Errors())
+
+	invalid := validMonitor()
+	invalid["id"] = "My Monitor"
+	invalid["status"] = "flapping"
+	invalid["duration"] = 1.5
+	invalid["ratio"] = 0.3
+	invalid["tags"] = []string{"a", "a"}
+	invalid["host"] = map[string]interface{}{"ip": 1}
+	invalid["unexpected"] = "x"
+
+	res = v(invalid)
+	assert.False(t, res.Valid)
+	for _, p := range []string{"id", "status", "duration", "ratio", "tags.[1]", "host.name", "host.ip", "unexpected"} {
+		assert.True(t, hasInvalid(res, p), "expected %s to be invalid", p)
+	}
+	assert.Equal(t, llresult.KeyMissingVR, res.Fields["host.name"][0])
+	assert.Equal(t, llresult.StrictFailureVR, res.Fields["unexpected"][0])
+}
+
+func TestCompileRequired(t *testing.T) {
+	v := MustCompile([]byte(monitorSchema))
+
+	doc := validMonitor()
+	delete(doc, "status")
+	delete(doc, "ratio")
+
+	res := v(doc)
+	assert.False(t, res.Valid)
+	assert.Equal(t, []llresult.ValueResult{llresult.KeyMissingVR}, res.Fields["status"])
+	assert.Empty(t, res.Fields["ratio"])
+}
+
+func TestCompileKeywordsApplyToTheirType(t *testing.T) {
+	v := MustCompile([]byte(`{"minLength": 2, "minimum": 5, "minItems": 1, "required": ["a"]}`))
+
+	for _, valid := range []interface{}{"ab", 7, []int{1}, map[string]interface{}{"a": nil}, true, nil} {
+		res := v(valid)
+		assert.True(t, res.Valid, "%#v: %v", valid, res.Errors())
+	}
+	for _, invalid := range []interface{}{"a", 4.5, []int{}, map[string]interface{}{}} {
+		assert.False(t, v(invalid).Valid, "%#v", invalid)
+	}
+}
+
+func TestCompileCombinators(t *testing.T) {
+	v := MustCompile([]byte(`{
+		"oneOf": [{"type": "integer"}, {"type": "number", "minimum": 10}],
+		"not": {"const": 3}
+	}`))
+
+	assert.True(t, v(2).Valid)
+	assert.True(t, v(10.5).Valid)
+	// Both branches match
+	assert.False(t, v(12).Valid)
+	assert.False(t, v(3).Valid)
+	assert.False(t, v("x").Valid)
+
+	all := MustCompile([]byte(`{"allOf": [{"type": "string"}, {"maxLength": 2}]}`))
+	assert.True(t, all("ab").Valid)
+	assert.False(t, all("abc").Valid)
+}
+
+func TestCompileEnumComparesNumbersByValue(t *testing.T) {
+	v := MustCompile([]byte(`{"enum": [1, [2, {"a": 3}]]}`))
+
+	assert.True(t, v(1).Valid)
+	assert.True(t, v(uint8(1)).Valid)
+	assert.True(t, v([]interface{}{2, map[string]int{"a": 3}}).Valid)
+	assert.False(t, v(1.5).Valid)
+	assert.False(t, v("1").Valid)
+}
+
+func TestCompileRequiredWithOptionalSchemas(t *testing.T) {
+	for _, schema := range []string{
+		`{"not": {"type": "string"}}`,
+		`{"allOf": [{"not": {"type": "string"}}]}`,
+		`{"oneOf": [{"not": {"type": "string"}}, {"type": "boolean"}]}`,
+	} {
+		v := MustCompile([]byte(`{"required": ["a"], "properties": {"a": ` + schema + `}}`))
+
+		res := v(map[string]interface{}{})
+		assert.False(t, res.Valid, schema)
+		assert.Equal(t, llresult.KeyMissingVR, res.Fields["a"][0], schema)
+		assert.True(t, v(map[string]interface{}{"a": 1}).Valid, schema)
+	}
+}
+
+func TestCompilePointerDocuments(t *testing.T) {
+	v := MustCompile([]byte(`{
+		"type": "object",
+		"required": ["tags"],
+		"propertyNames": {"pattern": "^[a-z]+$"},
+		"properties": {
+			"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true, "contains": {"const": "a"}}
+		}
+	}`))
+
+	tags := []interface{}{"a", "b"}
+	doc := map[string]interface{}{"tags": &tags}
+	assert.True(t, v(&doc).Valid)
+
+	dup := []interface{}{"a", "a"}
+	res := v(&map[string]interface{}{"tags": &dup, "Bad": 1})
+	assert.False(t, res.Valid)
+	assert.True(t, hasInvalid(res, "tags.[1]"))
+	assert.True(t, hasInvalid(res, "Bad"))
+}
+
+func TestCompileMultipleOf(t *testing.T) {
+	v := MustCompile([]byte(`{"multipleOf": 0.1}`))
+
+	assert.True(t, v(0.3).Valid)
+	assert.True(t, v(7).Valid)
+	assert.True(t, v(-1.2).Valid)
+	assert.False(t, v(0.35).Valid)
+
+	_, err := Compile([]byte(`{"multipleOf": 0}`))
+	assert.ErrorContains(t, err, "multipleOf must be greater than 0")
+	_, err = Compile([]byte(`{"multipleOf": -2}`))
+	assert.Error(t, err)
+}
+
+func TestCompileRecursiveRef(t *testing.T) {
+	v := MustCompile([]byte(`{
+		"$defs": {
+			"node": {
+				"type": "object",
+				"required": ["value"],
+				"properties": {
+					"value": {"type": "integer"},
+					"children": {"type": "array", "items": {"$ref": "#/$defs/node"}}
+				}
+			}
+		},
+		"$ref": "#/$defs/node"
+	}`))
+
+	tree := map[string]interface{}{
+		"value": 1,
+		"children": []interface{}{
+			map[string]interface{}{"value": 2},
+			map[string]interface{}{"value": "three"},
+		},
+	}
+	res := v(tree)
+	assert.False(t, res.Valid)
+	assert.True(t, hasInvalid(res, llpath.MustParsePath("children.[1].value").String()))
+	assert.False(t, hasInvalid(res, llpath.MustParsePath("children.[0].value").String()))
+}
+
+func TestCompileBooleanSchemas(t *testing.T) {
+	v := MustCompile([]byte(`{"properties": {"anything": true, "nothing": false}}`))
+
+	assert.True(t, v(map[string]interface{}{"anything": 1}).Valid)
+	assert.False(t, v(map[string]interface{}{"nothing": 1}).Valid)
+}
+
+func TestCompilePrefixItems(t *testing.T) {
+	v := MustCompile([]byte(`{
+		"type": "array",
+		"prefixItems": [{"type": "string"}, {"type": "integer"}],
+		"items": {"type": "boolean"},
+		"x-owner": "team-a"
+	}`))
+
+	assert.True(t, v([]interface{}{"a", 1, true, false}).Valid)
+	assert.True(t, v([]interface{}{"a"}).Valid)
+
+	res := v([]interface{}{"a", "b", 1})
+	assert.False(t, res.Valid)
+	assert.True(t, hasInvalid(res, "[1]"))
+	assert.True(t, hasInvalid(res, "[2]"))
+	assert.False(t, hasInvalid(res, "[0]"))
+}
+
+func TestCompileContains(t *testing.T) {
+	v := MustCompile([]byte(`{"contains": {"type": "integer", "minimum": 10}}`))
+
+	assert.True(t, v([]interface{}{"a", 12}).Valid)
+	assert.False(t, v([]interface{}{"a", 2}).Valid)
+	assert.False(t, v([]interface{}{}).Valid)
+	assert.True(t, v("not an array").Valid)
+}
+
+func TestCompilePropertyNames(t *testing.T) {
+	v := MustCompile([]byte(`{"propertyNames": {"pattern": "^[a-z]+$", "maxLength": 4}}`))
+
+	assert.True(t, v(map[string]interface{}{"ab": 1, "cd": 2}).Valid)
+
+	res := v(map[string]interface{}{"ab": 1, "Cd": 2, "abcde": 3})
+	assert.False(t, res.Valid)
+	assert.True(t, hasInvalid(res, "Cd"))
+	assert.True(t, hasInvalid(res, "abcde"))
+	assert.False(t, hasInvalid(res, "ab"))
+}
+
+func TestCompileErrors(t *testing.T) {
+	_, err := Compile([]byte(`{
+		"type": "strin",
+		"properties": {
+			"a": {"patternProperties": {}},
+			"b": {"pattern": "("},
+			"c": {"$ref": "#/$defs/missing"},
+			"d": {"minLength": -1}
+		},
+		"if": {}
+	}`))
+	require.Error(t, err)
+
+	var errs CompileErrors
+	require.ErrorAs(t, err, &errs)
+	var locations []string
+	for _, e := range errs {
+		locations = append(locations, e.Location)
+	}
+	assert.Equal(t, []string{
+		"#/if",
+		"#/properties/a/patternProperties",
+		"#/properties/b/pattern",
+		"#/properties/c/$ref",
+		"#/properties/d/minLength",
+		"#/type",
+	}, locations)
+	assert.Contains(t, err.Error(), "unsupported keyword 'patternProperties'")
+
+	_, err = Compile([]byte(`{"type": `))
+	assert.Error(t, err)
+}
@@ -0,0 +1,335 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package llresult
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/elastic/go-lookslike/internal/llreflect"
+	"github.com/elastic/go-lookslike/llpath"
+)
+
+// Redacted is shown in place of sensitive values.
+const Redacted = "[REDACTED]"
+
+// MarkSensitive records that the value at the given path is sensitive, so that it can be masked by a
+// Redaction when the results or the validated value are printed.
+func (r *Results) MarkSensitive(p llpath.Path) {
+	if r.sensitive == nil {
+		r.sensitive = map[string]bool{}
+	}
+	r.sensitive[p.String()] = true
+}
+
+// IsSensitive returns true if the value at the given path was marked as sensitive.
+func (r *Results) IsSensitive(p llpath.Path) bool {
+	return r.sensitive[p.String()]
+}
+
+func (r *Results) mergeSensitive(prefix llpath.Path, other *Results) {
+	for p := range other.sensitive {
+		r.MarkSensitive(prefix.Concat(llpath.MustParsePath(p)))
+	}
+}
+
+// RedactMessage replaces the usual textual representations of v in msg, such as %v and %q, with Redacted.
+// Maps and slices are redacted leaf by leaf, so their elements are masked wherever they appear, and only whole
+// tokens are replaced, so the 1 in 10 is left alone. Representations of the keep values, such as the
+// arguments of the IsDef that produced msg, are never replaced.
+func RedactMessage(msg string, v interface{}, keep ...interface{}) string {
+	// replaced marks the bytes of msg that are kept or already redacted
+	replaced := make([]bool, len(msg))
+	for _, repr := range leafRepresentations(keep) {
+		for _, loc := range tokenIndices(msg, repr) {
+			markRange(replaced, loc)
+		}
+	}
+
+	var redactions [][2]int
+	reprs := leafRepresentations([]interface{}{v})
+	// Replace longer representations first, since they may contain shorter ones
+	sort.SliceStable(reprs, func(i, j int) bool { return len(reprs[i]) > len(reprs[j]) })
+	for _, repr := range reprs {
+		for _, loc := range tokenIndices(msg, repr) {
+			if !anyMarked(replaced, loc) {
+				markRange(replaced, loc)
+				redactions = append(redactions, loc)
+			}
+		}
+	}
+	if len(redactions) == 0 {
+		return msg
+	}
+
+	sort.Slice(redactions, func(i, j int) bool { return redactions[i][0] < redactions[j][0] })
+	var b strings.Builder
+	last := 0
+	for _, loc := range redactions {
+		b.WriteString(msg[last:loc[0]])
+		b.WriteString(Redacted)
+		last = loc[1]
+	}
+	b.WriteString(msg[last:])
+	return b.String()
+}
+
+// leafRepresentations returns the representations of the leaves of the given values, walking maps and slices
+// as Redaction does.
+func leafRepresentations(values []interface{}) []string {
+	var reprs []string
+	seen := map[string]bool{}
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		chased := llreflect.ChaseValue(v)
+		switch chased.Kind() {
+		case reflect.Invalid:
+			return
+		case reflect.Map:
+			for _, k := range chased.MapKeys() {
+				walk(chased.MapIndex(k))
+			}
+			return
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < chased.Len(); i++ {
+				walk(chased.Index(i))
+			}
+			return
+		}
+		// Format the value before chasing pointers, so that String methods on pointers are used
+		for _, repr := range representations(v.Interface()) {
+			if !seen[repr] {
+				seen[repr] = true
+				reprs = append(reprs, repr)
+			}
+		}
+	}
+	for _, v := range values {
+		walk(reflect.ValueOf(v))
+	}
+	return reprs
+}
+
+// tokenIndices returns the locations of repr in msg that are not part of a longer word or number.
+func tokenIndices(msg, repr string) [][2]int {
+	var locs [][2]int
+	for offset := 0; offset < len(msg); {
+		i := strings.Index(msg[offset:], repr)
+		if i < 0 {
+			break
+		}
+		start, end := offset+i, offset+i+len(repr)
+		first, _ := utf8.DecodeRuneInString(repr)
+		last, _ := utf8.DecodeLastRuneInString(repr)
+		before, _ := utf8.DecodeLastRuneInString(msg[:start])
+		after, _ := utf8.DecodeRuneInString(msg[end:])
+		if !(isWordRune(first) && isWordRune(before)) && !(isWordRune(last) && isWordRune(after)) {
+			locs = append(locs, [2]int{start, end})
+		}
+		offset = start + 1
+	}
+	return locs
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func markRange(marks []bool, loc [2]int) {
+	for i := loc[0]; i < loc[1]; i++ {
+		marks[i] = true
+	}
+}
+
+func anyMarked(marks []bool, loc [2]int) bool {
+	for i := loc[0]; i < loc[1]; i++ {
+		if marks[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func representations(v interface{}) []string {
+	if v == nil {
+		return nil
+	}
+
+	candidates := []string{fmt.Sprintf("%v", v), fmt.Sprintf("%+v", v), fmt.Sprintf("%#v", v)}
+	if s, ok := v.(string); ok {
+		candidates = append(candidates, strconv.Quote(s), fmt.Sprintf("%q", s))
+	}
+
+	var reprs []string
+	seen := map[string]bool{}
+	for _, c := range candidates {
+		if c != "" && !seen[c] {
+			seen[c] = true
+			reprs = append(reprs, c)
+		}
+	}
+	return reprs
+}
+
+// Redaction masks sensitive values in Results and in the values they were validated against. Values are
+// sensitive if their path matches one of the Redaction's patterns or was marked with Results.MarkSensitive,
+// for instance by isdef.Sensitive. Everything below a sensitive path is sensitive too.
+// A nil *Redaction only masks marked values.
+type Redaction struct {
+	patterns [][]string
+}
+
+// NewRedaction creates a Redaction for the given path patterns. Patterns are dotted paths like those in
+// Results.Fields, where "*" matches any single key or slice index and "**" matches any number of them,
+// e.g. "**.password" or "headers.*".
+func NewRedaction(patterns ...string) (*Redaction, error) {
+	rd := &Redaction{}
+	for _, pattern := range patterns {
+		parts := strings.Split(pattern, ".")
+		for _, part := range parts {
+			if part == "" {
+				return nil, llpath.InvalidPathString(pattern)
+			}
+		}
+		rd.patterns = append(rd.patterns, parts)
+	}
+	return rd, nil
+}
+
+// MustNewRedaction is the panic-ing equivalent of NewRedaction.
+func MustNewRedaction(patterns ...string) *Redaction {
+	rd, err := NewRedaction(patterns...)
+	if err != nil {
+		panic(err)
+	}
+	return rd
+}
+
+// Matches returns true if the given path matches one of the Redaction's patterns.
+func (rd *Redaction) Matches(p llpath.Path) bool {
+	if rd == nil {
+		return false
+	}
+
+	parts := make([]string, len(p))
+	for i, pc := range p {
+		parts[i] = pc.String()
+	}
+	for _, pattern := range rd.patterns {
+		if matchParts(pattern, parts) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchParts(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchParts(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 || (pattern[0] != "*" && pattern[0] != parts[0]) {
+		return false
+	}
+	return matchParts(pattern[1:], parts[1:])
+}
+
+// Results returns a copy of r with every sensitive value in actual masked in all messages, and the matched
+// paths marked as sensitive.
+func (rd *Redaction) Results(r *Results, actual interface{}) *Results {
+	var secrets []interface{}
+	var paths []llpath.Path
+	rd.redact(r, llpath.Path{}, reflect.ValueOf(actual), false, func(p llpath.Path, v interface{}) {
+		paths = append(paths, p)
+		secrets = append(secrets, v)
+	})
+
+	out := NewResults()
+	out.Valid = r.Valid
+	for p, vrs := range r.Fields {
+		for _, vr := range vrs {
+			for _, secret := range secrets {
+				vr.Message = RedactMessage(vr.Message, secret)
+			}
+			out.Fields[p] = append(out.Fields[p], vr)
+		}
+	}
+	out.mergeSensitive(llpath.Path{}, r)
+	for _, p := range paths {
+		out.MarkSensitive(p)
+	}
+	return out
+}
+
+// Value returns a copy of actual, suitable for printing, with sensitive values replaced by Redacted.
+// Maps and slices are copied as map[string]interface{} and []interface{}.
+func (rd *Redaction) Value(r *Results, actual interface{}) interface{} {
+	return rd.redact(r, llpath.Path{}, reflect.ValueOf(actual), false, func(llpath.Path, interface{}) {})
+}
+
+// redact copies v, replacing values below sensitive paths with Redacted. found is called with every
+// sensitive value that is replaced.
+func (rd *Redaction) redact(
+	r *Results, path llpath.Path, v reflect.Value, sensitive bool, found func(llpath.Path, interface{}),
+) interface{} {
+	v = llreflect.ChaseValue(v)
+	if !v.IsValid() {
+		return nil
+	}
+	sensitive = sensitive || r.IsSensitive(path) || rd.Matches(path)
+
+	switch v.Kind() {
+	case reflect.Map:
+		if sensitive {
+			found(path, v.Interface())
+		}
+		out := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			key := fmt.Sprint(k.Interface())
+			out[key] = rd.redact(r, path.ExtendMap(key), v.MapIndex(k), sensitive, found)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if sensitive {
+			found(path, v.Interface())
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = rd.redact(r, path.ExtendSlice(i), v.Index(i), sensitive, found)
+		}
+		return out
+	}
+
+	if sensitive {
+		found(path, v.Interface())
+		return Redacted
+	}
+	return v.Interface()
+}
@@ -29,6 +29,8 @@ import (
 type Results struct {
 	Fields map[string][]ValueResult
 	Valid  bool
+	// sensitive holds the paths marked with MarkSensitive
+	sensitive map[string]bool
 }
 
 // ValueResult represents the result of checking a leaf value.
@@ -48,8 +50,12 @@ func NewResults() *Results {
 // SimpleResult provides a convenient and simple method for creating a *Results object for a single validation.
 // It's a very common way for validators to return a *Results object, and is generally simpler than
 // using SingleResult.
+// The message is only used as a format string if args are given, so pre-formatted messages may contain '%'.
 func SimpleResult(path llpath.Path, valid bool, msg string, args ...interface{}) *Results {
-	vr := ValueResult{valid, fmt.Sprintf(msg, args...)}
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	vr := ValueResult{valid, msg}
 	return SingleResult(path, vr)
 }
 
@@ -68,6 +74,7 @@ func (r *Results) Merge(other *Results) {
 			r.Record(llpath.MustParsePath(otherPath), valueResult)
 		}
 	}
+	r.mergeSensitive(llpath.Path{}, other)
 }
 
 // MergeUnderPrefix merges the given results at the path specified by the given prefix.
@@ -85,6 +92,7 @@ func (r *Results) MergeUnderPrefix(prefix llpath.Path, other *Results) {
 			r.Record(prefix.Concat(parsed), valueResult)
 		}
 	}
+	r.mergeSensitive(prefix, other)
 }
 
 // Record records a single path result to this instance.
@@ -127,6 +135,7 @@ func (r *Results) DetailedErrors() *Results {
 
 		return true
 	})
+	errors.mergeSensitive(llpath.Path{}, r)
 	return errors
 }
 
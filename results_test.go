@@ -20,10 +20,12 @@ package lookslike
 import (
 	"testing"
 
+	"github.com/elastic/go-lookslike/isdef"
 	"github.com/elastic/go-lookslike/llpath"
 	"github.com/elastic/go-lookslike/llresult"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEmpty(t *testing.T) {
@@ -49,3 +51,65 @@ func TestWithError(t *testing.T) {
 	assert.False(t, r.DetailedErrors().Valid)
 	assert.NotEmpty(t, r.Errors())
 }
+
+func TestSimpleResultPreformatted(t *testing.T) {
+	r := llresult.SimpleResult(llpath.MustParsePath("foo"), false, "100% wrong")
+	assert.Equal(t, "100% wrong", r.Fields["foo"][0].Message)
+
+	r = llresult.SimpleResult(llpath.MustParsePath("foo"), false, "%d%% wrong", 100)
+	assert.Equal(t, "100% wrong", r.Fields["foo"][0].Message)
+}
+
+func TestRedaction(t *testing.T) {
+	doc := map[string]interface{}{
+		"user":  "alice",
+		"token": "tok_live_123",
+		"auth": map[string]interface{}{
+			"password": "hunter2",
+			"methods":  []string{"otp", "pw"},
+		},
+		"headers": map[string]interface{}{"Authorization": "Bearer abc"},
+	}
+	v := MustCompile(map[string]interface{}{
+		"user":          isdef.IsEqual("bob"),
+		"token":         isdef.Sensitive(isdef.IsStringWithPrefix("tok_test_")),
+		"auth.password": isdef.IsEqual("hunter3"),
+		"headers":       isdef.IsMapWithKeys("Cookie"),
+	})
+
+	raw := v(doc)
+	require.False(t, raw.Valid)
+	// Sensitive masks its own messages
+	assert.NotContains(t, raw.Fields["token"][0].Message, "tok_live_123")
+	assert.Contains(t, raw.Fields["auth.password"][0].Message, "hunter2")
+
+	rd := llresult.MustNewRedaction("auth.**", "headers.*")
+	res := rd.Results(raw, doc)
+	assert.False(t, res.Valid)
+	assert.Len(t, res.Fields, len(raw.Fields))
+	assert.Contains(t, res.Fields["user"][0].Message, "alice")
+	assert.NotContains(t, res.Fields["auth.password"][0].Message, "hunter2")
+	assert.NotContains(t, res.Fields["headers"][0].Message, "Bearer abc")
+	for _, p := range []string{"token", "auth", "auth.password", "headers.Authorization"} {
+		assert.True(t, res.IsSensitive(llpath.MustParsePath(p)), p)
+	}
+	assert.False(t, res.IsSensitive(llpath.MustParsePath("user")))
+
+	assert.Equal(t, map[string]interface{}{
+		"user":  "alice",
+		"token": llresult.Redacted,
+		"auth": map[string]interface{}{
+			"password": llresult.Redacted,
+			"methods":  []interface{}{llresult.Redacted, llresult.Redacted},
+		},
+		"headers": map[string]interface{}{"Authorization": llresult.Redacted},
+	}, rd.Value(res, doc))
+
+	// A nil Redaction only masks values marked as sensitive
+	var none *llresult.Redaction
+	assert.Equal(t, llresult.Redacted, none.Value(raw, doc).(map[string]interface{})["token"])
+	assert.Equal(t, "hunter2", none.Value(raw, doc).(map[string]interface{})["auth"].(map[string]interface{})["password"])
+
+	_, err := llresult.NewRedaction("a..b")
+	assert.Error(t, err)
+}
@@ -0,0 +1,426 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+/*
+Package schemafile loads lookslike schemas written as YAML or JSON, so validations can be authored without
+writing Go. A schema file is a mapping of paths to matchers:
+
+	monitor.id: {is: string, matching: "^[a-z0-9-]+$"}
+	monitor.status: {oneOf: [up, down]}
+	monitor.duration.us: {gt: 0}
+	url.full: {is: url, optional: true}
+	error: {missing: true}
+	summary.up: 1
+	tags: [prod, {prefix: "team-"}]
+
+Values that are not mappings are matched exactly, as they are by lookslike.MustCompile, and sequences are
+matched element by element. Each mapping is a matcher whose keys are all checked:
+
+	is         a type or list of types: string, number, bool, map, slice, nil, uuid, email, ip, cidr, mac,
+	           url, hostname, port, duration or hex
+	matching   a regular expression the string must match
+	contains, prefix, suffix
+	           a substring the string must contain, start or end with
+	equals     a value that must be equal
+	oneOf      a list of values, one of which must be equal
+	gt, gte, lt, lte
+	           a number, string or time the value is compared to
+	anyOf, allOf, exactlyOne
+	           a list of matchers or values to combine
+	not        a matcher or value that must not match, the key must still be present
+	expr       a matcher expression, as accepted by isdef.ParseMatcher, e.g. "isStringLengthBetween(1, 64)"
+	optional   if true, the key may be missing
+	sensitive  if true, the value is masked in failure messages and when printed, see isdef.Sensitive
+	missing    if true, the key must be missing
+	present    if true, the key must be present, with any value
+
+Any other key is the name of a matcher in the isdef.Registry, with its argument, or a list of arguments, as the
+value, e.g. {isStringLengthBetween: [1, 64]}. Matchers registered with isdef.RegisterMatcher can be used in
+schema files loaded with Load, while a Loader can use its own Registry.
+
+Errors refer to the line and column of the definition at fault.
+*/
+package schemafile
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elastic/go-lookslike"
+	"github.com/elastic/go-lookslike/isdef"
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/validator"
+)
+
+// Error describes a problem with a definition in a schema file.
+type Error struct {
+	// File is the name of the schema file, if it was loaded with LoadFile.
+	File   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e Error) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Msg)
+	}
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Errors is returned with every problem found in a schema file.
+type Errors []Error
+
+func (es Errors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// types maps the names accepted by the "is" key to matcher names.
+var types = map[string]string{
+	"string":   "isString",
+	"number":   "isNumber",
+	"bool":     "isBool",
+	"map":      "isMap",
+	"slice":    "isSlice",
+	"nil":      "isNil",
+	"uuid":     "isUUID",
+	"email":    "isEmail",
+	"ip":       "isIP",
+	"cidr":     "isCIDR",
+	"mac":      "isMAC",
+	"url":      "isURL",
+	"hostname": "isHostname",
+	"port":     "isPort",
+	"duration": "isDuration",
+}
+
+// aliases maps the short keys of a matcher to the names of matchers that take a single argument.
+var aliases = map[string]string{
+	"matching": "isStringMatching",
+	"contains": "isStringContaining",
+	"prefix":   "isStringWithPrefix",
+	"suffix":   "isStringWithSuffix",
+	"gt":       "isGt",
+	"gte":      "isGte",
+	"lt":       "isLt",
+	"lte":      "isLte",
+}
+
+// combinators maps keys taking a list of matchers to the names of the matchers combining them.
+var combinators = map[string]string{
+	"anyOf":      "isAny",
+	"allOf":      "all",
+	"exactlyOne": "exactlyOne",
+	"oneOf":      "isAny",
+}
+
+// Loader loads schema files using the matchers of a Registry.
+type Loader struct {
+	// Registry holds the matchers schema files can refer to, isdef.DefaultRegistry if nil.
+	Registry *isdef.Registry
+}
+
+// Parse reads a schema file and returns its definition, which can be passed to lookslike.MustCompile or
+// combined with other definitions. Definition errors are returned as Errors.
+func Parse(data []byte) (map[string]interface{}, error) {
+	return Loader{}.Parse(data)
+}
+
+// Load reads a schema file and compiles it into a validator.Validator.
+func Load(data []byte) (validator.Validator, error) {
+	return Loader{}.Load(data)
+}
+
+// LoadFile is like Load, but reads the schema from the named file. Errors include the file name.
+func LoadFile(filename string) (validator.Validator, error) {
+	return Loader{}.LoadFile(filename)
+}
+
+// Parse is like the package level Parse, using the Loader's Registry.
+func (l Loader) Parse(data []byte) (map[string]interface{}, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	p := &parser{registry: l.Registry}
+	if p.registry == nil {
+		p.registry = isdef.DefaultRegistry
+	}
+	definition := map[string]interface{}{}
+	if len(doc.Content) == 0 {
+		return definition, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		p.fail(root, "a schema file must be a mapping of paths to matchers")
+		return nil, p.errs
+	}
+	for i := 0; i < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if _, err := llpath.ParsePath(key.Value); err != nil {
+			p.fail(key, "invalid path '%s': %s", key.Value, err)
+			continue
+		}
+		definition[key.Value] = p.value(value)
+	}
+
+	if len(p.errs) > 0 {
+		return nil, p.errs
+	}
+	return definition, nil
+}
+
+// Load is like the package level Load, using the Loader's Registry.
+func (l Loader) Load(data []byte) (validator.Validator, error) {
+	definition, err := l.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return lookslike.Compiler{}.Compile(definition)
+}
+
+// LoadFile is like the package level LoadFile, using the Loader's Registry.
+func (l Loader) LoadFile(filename string) (validator.Validator, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := l.Load(data)
+	if errs, ok := err.(Errors); ok {
+		for i := range errs {
+			errs[i].File = filename
+		}
+		return nil, errs
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return v, nil
+}
+
+type parser struct {
+	registry *isdef.Registry
+	errs     Errors
+}
+
+func (p *parser) fail(n *yaml.Node, msg string, args ...interface{}) {
+	p.errs = append(p.errs, Error{Line: n.Line, Column: n.Column, Msg: fmt.Sprintf(msg, args...)})
+}
+
+// value converts a node into a definition value: mappings become matchers, sequences are converted element
+// by element and scalars are decoded as they are.
+func (p *parser) value(n *yaml.Node) interface{} {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return p.value(n.Alias)
+	case yaml.MappingNode:
+		return p.matcher(n)
+	case yaml.SequenceNode:
+		values := make([]interface{}, len(n.Content))
+		for i, elem := range n.Content {
+			values[i] = p.value(elem)
+		}
+		return values
+	}
+
+	var v interface{}
+	if err := n.Decode(&v); err != nil {
+		p.fail(n, "%s", err)
+	}
+	return v
+}
+
+// def converts a node into an IsDef, matching values that are not mappings exactly.
+func (p *parser) def(n *yaml.Node) isdef.IsDef {
+	if n.Kind == yaml.MappingNode {
+		return p.matcher(n)
+	}
+	return p.build(n, "isEqual", p.literal(n))
+}
+
+// literal decodes a node as plain data, without treating mappings as matchers.
+func (p *parser) literal(n *yaml.Node) interface{} {
+	var v interface{}
+	if err := n.Decode(&v); err != nil {
+		p.fail(n, "%s", err)
+	}
+	return v
+}
+
+func (p *parser) build(n *yaml.Node, name string, args ...interface{}) isdef.IsDef {
+	def, err := p.registry.Build(name, args...)
+	if err != nil {
+		p.fail(n, "%s", err)
+		return isdef.KeyPresent
+	}
+	return def
+}
+
+func (p *parser) flag(n *yaml.Node) bool {
+	var b bool
+	if err := n.Decode(&b); err != nil {
+		p.fail(n, "expected true or false, got '%s'", n.Value)
+	}
+	return b
+}
+
+func (p *parser) matcher(n *yaml.Node) isdef.IsDef {
+	var defs []isdef.IsDef
+	optional, sensitive := false, false
+
+	for i := 0; i < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		switch name := key.Value; name {
+		case "optional":
+			optional = p.flag(value)
+		case "sensitive":
+			sensitive = p.flag(value)
+		case "missing":
+			if p.flag(value) {
+				defs = append(defs, isdef.KeyMissing)
+			}
+		case "present":
+			if p.flag(value) {
+				defs = append(defs, isdef.KeyPresent)
+			}
+		case "is":
+			defs = append(defs, p.types(value))
+		case "not":
+			// The key must be present whatever the inner matcher does with a missing key,
+			// only optional lets a missing key through
+			defs = append(defs, isdef.All(isdef.KeyPresent, isdef.Not(p.def(value))))
+		case "equals":
+			defs = append(defs, p.build(value, "isEqual", p.literal(value)))
+		case "expr":
+			defs = append(defs, p.expr(value))
+		default:
+			if combinator, ok := combinators[name]; ok {
+				if value.Kind != yaml.SequenceNode {
+					p.fail(value, "%s expects a list", name)
+					continue
+				}
+				args := make([]interface{}, len(value.Content))
+				for j, elem := range value.Content {
+					args[j] = p.def(elem)
+				}
+				defs = append(defs, p.build(value, combinator, args...))
+				continue
+			}
+
+			if alias, ok := aliases[name]; ok {
+				defs = append(defs, p.build(value, alias, p.literal(value)))
+				continue
+			}
+
+			var args []interface{}
+			if value.Kind == yaml.SequenceNode {
+				for _, elem := range value.Content {
+					args = append(args, p.arg(elem))
+				}
+			} else {
+				args = []interface{}{p.arg(value)}
+			}
+			defs = append(defs, p.build(key, name, args...))
+		}
+	}
+
+	var def isdef.IsDef
+	switch len(defs) {
+	case 0:
+		def = isdef.KeyPresent
+	case 1:
+		def = defs[0]
+	default:
+		def = isdef.All(defs...)
+	}
+	if sensitive {
+		def = isdef.Sensitive(def)
+	}
+	if optional {
+		def = isdef.Optional(def)
+	}
+	return def
+}
+
+// arg converts a node into an argument for a matcher, nested matchers become IsDefs.
+func (p *parser) arg(n *yaml.Node) interface{} {
+	if n.Kind == yaml.MappingNode {
+		return p.matcher(n)
+	}
+	return p.literal(n)
+}
+
+func (p *parser) expr(n *yaml.Node) isdef.IsDef {
+	def, err := p.registry.Parse(n.Value)
+	if err == nil {
+		return def
+	}
+
+	e := Error{Line: n.Line, Column: n.Column, Msg: err.Error()}
+	if exprErr, ok := err.(isdef.ExprError); ok && n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+		// Point at the problem within the expression, after the opening quote if there is one
+		e.Column += exprErr.Pos
+		if n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+			e.Column++
+		}
+	}
+	p.errs = append(p.errs, e)
+	return isdef.KeyPresent
+}
+
+func (p *parser) types(n *yaml.Node) isdef.IsDef {
+	nodes := []*yaml.Node{n}
+	if n.Kind == yaml.SequenceNode {
+		nodes = n.Content
+	}
+
+	defs := make([]isdef.IsDef, 0, len(nodes))
+	for _, t := range nodes {
+		if t.Value == "hex" {
+			defs = append(defs, p.build(t, "isHex", 0))
+			continue
+		}
+		name, ok := types[t.Value]
+		if !ok || t.Kind != yaml.ScalarNode {
+			known := make([]string, 0, len(types)+1)
+			for k := range types {
+				known = append(known, k)
+			}
+			known = append(known, "hex")
+			sort.Strings(known)
+			p.fail(t, "unknown type '%s', expected one of %s", t.Value, strings.Join(known, ", "))
+			continue
+		}
+		defs = append(defs, p.build(t, name))
+	}
+
+	if len(defs) == 1 {
+		return defs[0]
+	}
+	return isdef.IsAny(defs...)
+}
@@ -0,0 +1,231 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schemafile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastic/go-lookslike"
+	"github.com/elastic/go-lookslike/isdef"
+	"github.com/elastic/go-lookslike/llresult"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const monitorSchema = `
+# Checks for a heartbeat event
+monitor.id: {is: string, matching: "^[a-z0-9-]+$"}
+monitor.status: {oneOf: [up, down]}
+monitor.duration.us: {gt: 0}
+monitor.ip: {is: ip, optional: true}
+url.full: {is: url, prefix: "https://"}
+error: {missing: true}
+summary.up: 1
+tags: [prod, {isStringLengthBetween: [3, 10]}]
+host: {anyOf: [{is: nil}, {is: map}]}
+`
+
+func validMonitor() map[string]interface{} {
+	return map[string]interface{}{
+		"monitor": map[string]interface{}{
+			"id":       "my-monitor",
+			"status":   "up",
+			"duration": map[string]interface{}{"us": 1500},
+		},
+		"url":     map[string]interface{}{"full": "https://example.com"},
+		"summary": map[string]interface{}{"up": 1},
+		"tags":    []string{"prod", "team-a"},
+		"host":    nil,
+	}
+}
+
+func TestLoad(t *testing.T) {
+	v, err := Load([]byte(monitorSchema))
+	require.NoError(t, err)
+
+	res := v(validMonitor())
+	assert.True(t, res.Valid, "%v", res.Errors())
+
+	doc := validMonitor()
+	doc["monitor"] = map[string]interface{}{
+		"id":       "My Monitor",
+		"status":   "flapping",
+		"duration": map[string]interface{}{"us": 0},
+		"ip":       "nope",
+	}
+	doc["url"] = map[string]interface{}{"full": "http://example.com"}
+	doc["error"] = "boom"
+	doc["tags"] = []string{"prod", "a"}
+	doc["host"] = "web-1"
+
+	res = v(doc)
+	assert.False(t, res.Valid)
+	for _, p := range []string{
+		"monitor.id", "monitor.status", "monitor.duration.us", "monitor.ip",
+		"url.full", "error", "tags.[1]", "host",
+	} {
+		assert.True(t, hasInvalid(res, p), "expected %s to be invalid", p)
+	}
+}
+
+func hasInvalid(res *llresult.Results, path string) bool {
+	for _, vr := range res.Fields[path] {
+		if !vr.Valid {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLoadJSON(t *testing.T) {
+	v, err := Load([]byte(`{
+		"id": {"is": ["string", "number"], "not": {"equals": "root"}},
+		"count": {"gte": 1, "lte": 10}
+	}`))
+	require.NoError(t, err)
+
+	assert.True(t, v(map[string]interface{}{"id": "a", "count": 1}).Valid)
+	assert.True(t, v(map[string]interface{}{"id": 5, "count": 10.0}).Valid)
+	assert.False(t, v(map[string]interface{}{"id": "root", "count": 5}).Valid)
+	assert.False(t, v(map[string]interface{}{"id": true, "count": 11}).Valid)
+}
+
+func TestLoadNotRequiresKey(t *testing.T) {
+	v, err := Load([]byte(`monitor.status: {not: down}`))
+	require.NoError(t, err)
+
+	up := map[string]interface{}{"monitor": map[string]interface{}{"status": "up"}}
+	assert.True(t, v(up).Valid)
+	down := map[string]interface{}{"monitor": map[string]interface{}{"status": "down"}}
+	assert.False(t, v(down).Valid)
+
+	missing := map[string]interface{}{"monitor": map[string]interface{}{}}
+	res := v(missing)
+	assert.False(t, res.Valid)
+	assert.Equal(t, []llresult.ValueResult{llresult.KeyMissingVR}, res.Fields["monitor.status"])
+
+	v, err = Load([]byte(`monitor.status: {not: down, optional: true}`))
+	require.NoError(t, err)
+	assert.True(t, v(missing).Valid)
+	assert.False(t, v(down).Valid)
+}
+
+func TestParseCombinesWithGoSchemas(t *testing.T) {
+	definition, err := Parse([]byte(`name: {is: string}`))
+	require.NoError(t, err)
+
+	definition["age"] = isdef.IsIntGt(0)
+	v := lookslike.Strict(lookslike.MustCompile(definition))
+
+	assert.True(t, v(map[string]interface{}{"name": "x", "age": 3}).Valid)
+	assert.False(t, v(map[string]interface{}{"name": "x", "age": 3, "extra": 1}).Valid)
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse([]byte(`
+a: {is: strin}
+b: {matching: "("}
+c: {isNothing: 1}
+"d..e": 1
+f: {optional: maybe}
+g: {anyOf: x}
+`))
+	require.Error(t, err)
+
+	errs, ok := err.(Errors)
+	require.True(t, ok, "%v", err)
+	require.Len(t, errs, 6)
+
+	assert.Equal(t, Error{Line: 2, Column: 9, Msg: errs[0].Msg}, errs[0])
+	assert.Contains(t, errs[0].Msg, "unknown type 'strin', expected one of bool, cidr")
+	assert.Equal(t, 3, errs[1].Line)
+	assert.Equal(t, 15, errs[1].Column)
+	assert.Contains(t, errs[1].Msg, "error parsing regexp")
+	assert.Equal(t, "line 4, column 5: unknown matcher 'isNothing'", errs[2].Error())
+	assert.Equal(t, 5, errs[3].Line)
+	assert.Contains(t, errs[3].Msg, "invalid path 'd..e'")
+	assert.Equal(t, "line 6, column 15: expected true or false, got 'maybe'", errs[4].Error())
+	assert.Equal(t, "line 7, column 12: anyOf expects a list", errs[5].Error())
+
+	_, err = Parse([]byte(`[a, b]`))
+	assert.EqualError(t, err, "line 1, column 1: a schema file must be a mapping of paths to matchers")
+
+	_, err = Parse([]byte("a: {\n"))
+	assert.Error(t, err)
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.yml")
+	require.NoError(t, os.WriteFile(good, []byte(monitorSchema), 0o644))
+
+	v, err := LoadFile(good)
+	require.NoError(t, err)
+	assert.True(t, v(validMonitor()).Valid)
+
+	bad := filepath.Join(dir, "bad.yml")
+	require.NoError(t, os.WriteFile(bad, []byte("a: {gt: 1, is: what}\n"), 0o644))
+
+	_, err = LoadFile(bad)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), bad+":1:16: unknown type 'what'")
+
+	_, err = LoadFile(filepath.Join(dir, "missing.yml"))
+	assert.Error(t, err)
+}
+
+func TestLoaderRegistry(t *testing.T) {
+	r := isdef.NewRegistry()
+	r.MustRegister(isdef.Matcher{
+		Name: "isEven",
+		Build: func([]interface{}) (isdef.IsDef, error) {
+			return isdef.Satisfies("is even", func(n int) bool { return n%2 == 0 }), nil
+		},
+	})
+
+	schema := []byte(`
+count: {isEven: []}
+name: {expr: "all(isString, isStringLengthBetween(1, 3))"}
+`)
+	v, err := Loader{Registry: r}.Load(schema)
+	require.NoError(t, err)
+	assert.True(t, v(map[string]interface{}{"count": 2, "name": "abc"}).Valid)
+	assert.False(t, v(map[string]interface{}{"count": 3, "name": "abcd"}).Valid)
+
+	// The default registry does not know about isEven
+	_, err = Load(schema)
+	assert.EqualError(t, err, "line 2, column 9: unknown matcher 'isEven'")
+
+	_, err = Load([]byte(`name: {expr: "isIntGt(x)"}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 1, column 23: invalid matcher expression 'isIntGt(x)' at position 8")
+}
+
+func TestSensitive(t *testing.T) {
+	v, err := Load([]byte(`password: {is: string, matching: "^[a-z]+$", sensitive: true}`))
+	require.NoError(t, err)
+
+	res := v(map[string]interface{}{"password": "Hunter2"})
+	assert.False(t, res.Valid)
+	for _, err := range res.Errors() {
+		assert.NotContains(t, err.Error(), "Hunter2")
+	}
+}
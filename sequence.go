@@ -0,0 +1,286 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lookslike
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/elastic/go-lookslike/llpath"
+	"github.com/elastic/go-lookslike/llresult"
+	"github.com/elastic/go-lookslike/validator"
+)
+
+// A Step matches one or more consecutive documents in a Sequence.
+type Step struct {
+	desc      string
+	validator validator.Validator
+	min, max  int                   // max is negative for steps without an upper bound
+	unordered []validator.Validator // set for InAnyOrder steps
+}
+
+// Exactly matches exactly n consecutive documents valid for the given validator.
+func Exactly(n int, v validator.Validator) Step {
+	return Step{desc: fmt.Sprintf("exactly %d", n), validator: v, min: n, max: n}
+}
+
+// AtLeast matches n or more consecutive documents valid for the given validator.
+func AtLeast(n int, v validator.Validator) Step {
+	return Step{desc: fmt.Sprintf("at least %d", n), validator: v, min: n, max: -1}
+}
+
+// AnyNumber matches any number of consecutive documents valid for the given validator, including none.
+func AnyNumber(v validator.Validator) Step {
+	return Step{desc: "any number", validator: v, min: 0, max: -1}
+}
+
+// InAnyOrder matches one consecutive document per given validator, in any order.
+func InAnyOrder(validators ...validator.Validator) Step {
+	return Step{desc: fmt.Sprintf("%d in any order", len(validators)), unordered: validators}
+}
+
+// Sequence creates a validator.Validator for an ordered series of documents, given as a slice, an array or
+// a channel. A channel is read until it is closed. Every document must be matched by the steps in order,
+// trying the longest match for each step first and backtracking when later steps cannot match.
+// Results for each document are recorded under its index, so "[2].monitor.status" refers to the third document.
+// If the documents cannot be matched, the failure is reported at the first document that no step matched
+// in the attempt that got furthest, naming the step it failed.
+func Sequence(steps ...Step) validator.Validator {
+	return func(actual interface{}) *llresult.Results {
+		docs, err := sequenceDocuments(actual)
+		if err != nil {
+			return llresult.SimpleResult(llpath.Path{}, false, err.Error())
+		}
+
+		sm := &sequenceMatch{
+			steps:   steps,
+			docs:    docs,
+			cache:   map[[3]int]*llresult.Results{},
+			dead:    map[[2]int]bool{},
+			failure: sequenceFailure{docIdx: -1},
+		}
+		if sm.match(0, 0) {
+			res := llresult.NewResults()
+			for docIdx, docRes := range sm.matched {
+				res.MergeUnderPrefix(llpath.Path{}.ExtendSlice(docIdx), docRes)
+			}
+			return res
+		}
+		return sm.failure.results()
+	}
+}
+
+func sequenceDocuments(actual interface{}) ([]interface{}, error) {
+	v := reflect.ValueOf(actual)
+	var docs []interface{}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			docs = append(docs, v.Index(i).Interface())
+		}
+	case reflect.Chan:
+		for {
+			doc, ok := v.Recv()
+			if !ok {
+				break
+			}
+			docs = append(docs, doc.Interface())
+		}
+	default:
+		return nil, fmt.Errorf("expected a slice or channel of documents, got %T", actual)
+	}
+	return docs, nil
+}
+
+// sequenceMatch holds the state of matching documents against steps.
+type sequenceMatch struct {
+	steps []Step
+	docs  []interface{}
+	// cache holds the results of validating a document, keyed by step, validator and document index
+	cache map[[3]int]*llresult.Results
+	// dead holds the step and document index pairs already known not to lead to a match
+	dead    map[[2]int]bool
+	matched []*llresult.Results
+	failure sequenceFailure
+}
+
+// sequenceFailure describes why a match attempt failed.
+type sequenceFailure struct {
+	docIdx int
+	atEnd  bool // true if the documents ran out, in which case the failure is reported at the root
+	msg    string
+	res    *llresult.Results
+}
+
+func (f sequenceFailure) results() *llresult.Results {
+	path := llpath.Path{}
+	if !f.atEnd {
+		path = path.ExtendSlice(f.docIdx)
+	}
+	res := llresult.SimpleResult(path, false, f.msg)
+	if f.res != nil {
+		res.MergeUnderPrefix(path, f.res.DetailedErrors())
+	}
+	return res
+}
+
+func (sm *sequenceMatch) fail(docIdx int, msg string, res *llresult.Results) {
+	// Only the attempt that got furthest is reported, as it is the most likely to be the real problem
+	if docIdx > sm.failure.docIdx {
+		sm.failure = sequenceFailure{docIdx: docIdx, atEnd: docIdx == len(sm.docs), msg: msg, res: res}
+	}
+}
+
+func (sm *sequenceMatch) check(stepIdx int, v validator.Validator, vIdx, docIdx int) *llresult.Results {
+	key := [3]int{stepIdx, vIdx, docIdx}
+	if res, ok := sm.cache[key]; ok {
+		return res
+	}
+	res := v(sm.docs[docIdx])
+	sm.cache[key] = res
+	return res
+}
+
+// match returns true if the documents from docIdx onwards can be matched by the steps from stepIdx
+// onwards, recording the results of every matched document in sm.matched.
+func (sm *sequenceMatch) match(stepIdx, docIdx int) bool {
+	if stepIdx == len(sm.steps) {
+		if docIdx == len(sm.docs) {
+			sm.matched = make([]*llresult.Results, len(sm.docs))
+			return true
+		}
+		sm.fail(docIdx, fmt.Sprintf("Document [%d] is unexpected, all steps were already matched", docIdx), nil)
+		return false
+	}
+
+	key := [2]int{stepIdx, docIdx}
+	if sm.dead[key] {
+		return false
+	}
+
+	step := sm.steps[stepIdx]
+	var ok bool
+	if step.unordered != nil {
+		ok = sm.matchUnordered(stepIdx, docIdx)
+	} else {
+		ok = sm.matchRepeated(stepIdx, docIdx)
+	}
+
+	if !ok {
+		sm.dead[key] = true
+	}
+	return ok
+}
+
+func (sm *sequenceMatch) matchRepeated(stepIdx, docIdx int) bool {
+	step := sm.steps[stepIdx]
+
+	run := 0
+	for docIdx+run < len(sm.docs) && (step.max < 0 || run < step.max) {
+		res := sm.check(stepIdx, step.validator, 0, docIdx+run)
+		if !res.Valid {
+			if run < step.min {
+				sm.fail(docIdx+run, fmt.Sprintf(
+					"Document [%d] did not match step %d (%s), which has matched %d documents so far",
+					docIdx+run, stepIdx, step.desc, run,
+				), res)
+			}
+			break
+		}
+		run++
+	}
+	if run < step.min {
+		if docIdx+run == len(sm.docs) {
+			sm.fail(len(sm.docs), fmt.Sprintf(
+				"Sequence ended after %d documents, but step %d (%s) only matched %d",
+				len(sm.docs), stepIdx, step.desc, run,
+			), nil)
+		}
+		return false
+	}
+
+	// Try the longest match first, backtracking to shorter ones if later steps fail
+	for count := run; count >= step.min; count-- {
+		if sm.match(stepIdx+1, docIdx+count) {
+			for i := 0; i < count; i++ {
+				sm.matched[docIdx+i] = sm.check(stepIdx, step.validator, 0, docIdx+i)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func (sm *sequenceMatch) matchUnordered(stepIdx, docIdx int) bool {
+	step := sm.steps[stepIdx]
+	k := len(step.unordered)
+	if docIdx+k > len(sm.docs) {
+		sm.fail(len(sm.docs), fmt.Sprintf(
+			"Sequence ended after %d documents, but step %d (%s) needed %d documents from [%d]",
+			len(sm.docs), stepIdx, step.desc, k, docIdx,
+		), nil)
+		return false
+	}
+
+	// Pair each validator with a distinct document using augmenting paths
+	docForValidator := make([]int, k)
+	validatorForDoc := make([]int, k)
+	for i := range validatorForDoc {
+		validatorForDoc[i] = -1
+	}
+	var augment func(vIdx int, seen []bool) bool
+	augment = func(vIdx int, seen []bool) bool {
+		for offset := 0; offset < k; offset++ {
+			if seen[offset] || !sm.check(stepIdx, step.unordered[vIdx], vIdx, docIdx+offset).Valid {
+				continue
+			}
+			seen[offset] = true
+			if validatorForDoc[offset] < 0 || augment(validatorForDoc[offset], seen) {
+				validatorForDoc[offset] = vIdx
+				docForValidator[vIdx] = offset
+				return true
+			}
+		}
+		return false
+	}
+	for vIdx := range step.unordered {
+		if !augment(vIdx, make([]bool, k)) {
+			// Report the first document of the block that nothing could be paired with
+			failedDoc := docIdx
+			for offset, v := range validatorForDoc {
+				if v < 0 {
+					failedDoc = docIdx + offset
+					break
+				}
+			}
+			sm.fail(failedDoc, fmt.Sprintf(
+				"Documents [%d] to [%d] did not match step %d (%s), expected document %d of the step was not found",
+				docIdx, docIdx+k-1, stepIdx, step.desc, vIdx,
+			), nil)
+			return false
+		}
+	}
+
+	if !sm.match(stepIdx+1, docIdx+k) {
+		return false
+	}
+	for vIdx, offset := range docForValidator {
+		sm.matched[docIdx+offset] = sm.check(stepIdx, step.unordered[vIdx], vIdx, docIdx+offset)
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lookslike
+
+import (
+	"testing"
+
+	"github.com/elastic/go-lookslike/isdef"
+	"github.com/elastic/go-lookslike/llresult"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	isDown    = MustCompile(map[string]interface{}{"status": "down"})
+	isUp      = MustCompile(map[string]interface{}{"status": "up"})
+	isSummary = MustCompile(map[string]interface{}{"summary": isdef.KeyPresent})
+)
+
+func status(s string) map[string]interface{} {
+	return map[string]interface{}{"status": s}
+}
+
+var summary = map[string]interface{}{"summary": true}
+
+func TestSequence(t *testing.T) {
+	v := Sequence(Exactly(1, isDown), AtLeast(1, isUp), Exactly(1, isSummary))
+
+	res := v([]interface{}{status("down"), status("up"), status("up"), summary})
+	assertResults(t, res)
+	assert.True(t, res.Fields["[0].status"][0].Valid)
+	assert.True(t, res.Fields["[2].status"][0].Valid)
+	assert.True(t, res.Fields["[3].summary"][0].Valid)
+
+	// Any slice type works
+	assertResults(t, v([]map[string]interface{}{status("down"), status("up"), summary}))
+
+	res = v([]interface{}{status("down"), status("down"), summary})
+	require.False(t, res.Valid)
+	assert.Contains(t, res.Fields["[1]"][0].Message, "did not match step 1 (at least 1)")
+	assert.False(t, res.Fields["[1].status"][0].Valid)
+
+	res = v([]interface{}{status("down"), status("up")})
+	require.False(t, res.Valid)
+	assert.Contains(t, res.Fields[""][0].Message, "Sequence ended after 2 documents, but step 2 (exactly 1)")
+
+	res = v([]interface{}{status("down"), status("up"), summary, summary})
+	require.False(t, res.Valid)
+	assert.Contains(t, res.Fields["[3]"][0].Message, "unexpected")
+
+	res = v("not a sequence")
+	assert.False(t, res.Valid)
+}
+
+func TestSequenceBacktracks(t *testing.T) {
+	// A greedy AnyNumber would consume the final up document
+	v := Sequence(AnyNumber(isUp), Exactly(1, isUp), Exactly(1, isSummary))
+	assertResults(t, v([]interface{}{status("up"), status("up"), summary}))
+	assertResults(t, v([]interface{}{status("up"), summary}))
+	assert.False(t, v([]interface{}{summary}).Valid)
+
+	empty := Sequence(AnyNumber(isUp))
+	assertResults(t, empty([]interface{}{}))
+}
+
+func TestSequenceInAnyOrder(t *testing.T) {
+	v := Sequence(InAnyOrder(isUp, isDown), Exactly(1, isSummary))
+
+	assertResults(t, v([]interface{}{status("up"), status("down"), summary}))
+	res := v([]interface{}{status("down"), status("up"), summary})
+	assertResults(t, res)
+	assert.True(t, res.Fields["[0].status"][0].Valid)
+
+	res = v([]interface{}{status("up"), status("up"), summary})
+	require.False(t, res.Valid)
+	assert.Contains(t, res.Errors()[0].Error(), "did not match step 0 (2 in any order)")
+
+	res = v([]interface{}{status("up")})
+	assert.False(t, res.Valid)
+}
+
+func TestSequenceChannel(t *testing.T) {
+	ch := make(chan map[string]interface{}, 3)
+	ch <- status("down")
+	ch <- status("up")
+	ch <- summary
+	close(ch)
+
+	v := Sequence(Exactly(1, isDown), AtLeast(1, isUp), Exactly(1, isSummary))
+	assertResults(t, v(ch))
+}
+
+func TestSequenceAsIsDef(t *testing.T) {
+	v := MustCompile(map[string]interface{}{
+		"events": isdef.IsSchema(Sequence(Exactly(1, isDown), Exactly(1, isSummary))),
+	})
+
+	res := v(map[string]interface{}{"events": []interface{}{status("down"), status("up")}})
+	require.False(t, res.Valid)
+	assert.Equal(t, []llresult.ValueResult{{Valid: false, Message: "Document [1] did not match step 1 (exactly 1), which has matched 0 documents so far"}}, res.Fields["events.[1]"])
+}
@@ -0,0 +1,326 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+/*
+Package snapshot generates lookslike schemas from sample documents, either as data that can be passed to
+lookslike.MustCompile or as Go source. Values are matched exactly unless a Rule replaces them with an IsDef,
+which is how volatile fields such as timestamps and IDs are handled.
+
+To bootstrap a test, generate a Go file with Generator.Golden, then run the tests with LOOKSLIKE_UPDATE=1
+whenever the documents change to regenerate it. Tests that prefer a flag can register their own and set
+Generator.Update from it.
+*/
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-lookslike/isdef"
+	"github.com/elastic/go-lookslike/llpath"
+)
+
+// UpdateEnv is the environment variable that makes Golden rewrite existing files when set to a non-empty value.
+const UpdateEnv = "LOOKSLIKE_UPDATE"
+
+const isdefImport = "github.com/elastic/go-lookslike/isdef"
+
+// Rule replaces the values it matches with an IsDef in generated schemas.
+type Rule struct {
+	// Match reports whether the rule applies to the value at the given path.
+	Match func(path llpath.Path, v interface{}) bool
+	// IsDef is used in place of the value in schemas generated as data.
+	IsDef isdef.IsDef
+	// GoExpr is the Go source for IsDef, used in schemas generated as Go source.
+	GoExpr string
+	// Imports lists the packages GoExpr needs.
+	Imports []string
+}
+
+func newRule(match func(path llpath.Path, v interface{}) bool, def isdef.IsDef, goExpr string, imports []string) Rule {
+	if strings.HasPrefix(goExpr, "isdef.") {
+		imports = append(imports, isdefImport)
+	}
+	return Rule{Match: match, IsDef: def, GoExpr: goExpr, Imports: imports}
+}
+
+// PathRule matches values whose dotted path, such as "monitor.id" or "events.[0].id", matches the given
+// regexp. It panics if pattern is not a valid regexp. Imports for isdef are added automatically.
+func PathRule(pattern string, def isdef.IsDef, goExpr string, imports ...string) Rule {
+	re := regexp.MustCompile(pattern)
+	return newRule(func(path llpath.Path, _ interface{}) bool {
+		return re.MatchString(path.String())
+	}, def, goExpr, imports)
+}
+
+// TypeRule matches values of the same type as sample.
+func TypeRule(sample interface{}, def isdef.IsDef, goExpr string, imports ...string) Rule {
+	t := reflect.TypeOf(sample)
+	return newRule(func(_ llpath.Path, v interface{}) bool {
+		return reflect.TypeOf(v) == t
+	}, def, goExpr, imports)
+}
+
+// DefaultRules replaces time.Time values, time.Duration values and UUID strings, which rarely stay the same
+// between runs, with type matchers.
+var DefaultRules = []Rule{
+	TypeRule(time.Time{}, isdef.IsType[time.Time](), "isdef.IsType[time.Time]()", "time"),
+	TypeRule(time.Duration(0), isdef.IsDuration, "isdef.IsDuration"),
+	newRule(func(path llpath.Path, v interface{}) bool {
+		return isdef.IsUUID.Check(path, v, true).Valid
+	}, isdef.IsUUID, "isdef.IsUUID", nil),
+}
+
+// Generator generates schemas from sample documents. The first matching Rule is used for each value.
+type Generator struct {
+	Rules []Rule
+	// Update makes Golden rewrite files that already exist, as setting UpdateEnv does.
+	Update bool
+}
+
+// NewGenerator returns a Generator using the given rules followed by DefaultRules.
+func NewGenerator(rules ...Rule) Generator {
+	return Generator{Rules: append(append([]Rule{}, rules...), DefaultRules...)}
+}
+
+func (g Generator) rule(path llpath.Path, v interface{}) *Rule {
+	for i := range g.Rules {
+		if g.Rules[i].Match(path, v) {
+			return &g.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Schema returns a schema for doc that can be compiled with lookslike.MustCompile. Maps become
+// map[string]interface{} and non-empty slices become []interface{}, with values matched by a Rule replaced
+// by its IsDef. All other values, including empty slices, are used as is so they are compared exactly.
+func (g Generator) Schema(doc interface{}) interface{} {
+	return g.schema(llpath.Path{}, doc)
+}
+
+func (g Generator) schema(path llpath.Path, v interface{}) interface{} {
+	if r := g.rule(path, v); r != nil {
+		return r.IsDef
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String || rv.Len() == 0 {
+			return v
+		}
+		out := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			k := iter.Key().String()
+			out[k] = g.schema(path.ExtendMap(k), valueInterface(iter.Value()))
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if rv.Len() == 0 {
+			return v
+		}
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			out[i] = g.schema(path.ExtendSlice(i), valueInterface(rv.Index(i)))
+		}
+		return out
+	}
+	return v
+}
+
+func valueInterface(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Interface && v.IsNil() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// GoExpr returns a Go expression for the schema of doc, along with the import paths it needs.
+func (g Generator) GoExpr(doc interface{}) (expr string, imports []string, err error) {
+	sw := sourceWriter{g: g, imports: map[string]bool{}}
+	if err := sw.write(llpath.Path{}, doc); err != nil {
+		return "", nil, err
+	}
+	for imp := range sw.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	return sw.buf.String(), imports, nil
+}
+
+// GoSource returns a formatted Go file in the given package declaring a variable with the given name that
+// holds the compiled schema of doc.
+func (g Generator) GoSource(pkg, varName string, doc interface{}) ([]byte, error) {
+	expr, imports, err := g.GoExpr(doc)
+	if err != nil {
+		return nil, err
+	}
+	imports = append(imports, "github.com/elastic/go-lookslike")
+	sort.Strings(imports)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by lookslike snapshot. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n", pkg)
+	for _, imp := range imports {
+		fmt.Fprintf(&buf, "\t%q\n", imp)
+	}
+	fmt.Fprintf(&buf, ")\n\nvar %s = lookslike.MustCompile(%s)\n", varName, expr)
+
+	return format.Source(buf.Bytes())
+}
+
+// Golden writes the Go source for the schema of doc to filename, as GoSource does, if the file does not
+// exist yet or updating is enabled with Update or UpdateEnv. It returns true if the file was written. The
+// generated variable is then used by the tests on the next build.
+func (g Generator) Golden(filename, pkg, varName string, doc interface{}) (bool, error) {
+	update := g.Update || os.Getenv(UpdateEnv) != ""
+	if _, err := os.Stat(filename); err == nil && !update {
+		return false, nil
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	src, err := g.GoSource(pkg, varName, doc)
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(filename, src, 0644)
+}
+
+// sourceWriter renders schemas as Go source.
+type sourceWriter struct {
+	g       Generator
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+func (sw *sourceWriter) write(path llpath.Path, v interface{}) error {
+	if r := sw.g.rule(path, v); r != nil {
+		sw.buf.WriteString(r.GoExpr)
+		for _, imp := range r.Imports {
+			sw.imports[imp] = true
+		}
+		return nil
+	}
+
+	if v == nil {
+		sw.buf.WriteString("nil")
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	t := rv.Type()
+	switch rv.Kind() {
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String || rv.Len() == 0 {
+			return sw.writeLiteral(path, v)
+		}
+		keys := make([]string, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+
+		sw.buf.WriteString("map[string]interface{}{\n")
+		for _, k := range keys {
+			fmt.Fprintf(&sw.buf, "%q: ", k)
+			mv := rv.MapIndex(reflect.ValueOf(k).Convert(t.Key()))
+			if err := sw.write(path.ExtendMap(k), valueInterface(mv)); err != nil {
+				return err
+			}
+			sw.buf.WriteString(",\n")
+		}
+		sw.buf.WriteString("}")
+		return nil
+	case reflect.Slice, reflect.Array:
+		if rv.Len() == 0 {
+			return sw.writeLiteral(path, v)
+		}
+		sw.buf.WriteString("[]interface{}{\n")
+		for i := 0; i < rv.Len(); i++ {
+			if err := sw.write(path.ExtendSlice(i), valueInterface(rv.Index(i))); err != nil {
+				return err
+			}
+			sw.buf.WriteString(",\n")
+		}
+		sw.buf.WriteString("}")
+		return nil
+	}
+	return sw.writeLiteral(path, v)
+}
+
+// writeLiteral writes v as a Go literal that keeps its exact type, since IsEqual compares types as well as values.
+func (sw *sourceWriter) writeLiteral(path llpath.Path, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	t := rv.Type()
+
+	// Named types would need imports we cannot infer, so only builtin types are supported
+	if t.PkgPath() != "" {
+		return fmt.Errorf("cannot generate source for value of type %s at '%s', add a Rule for it", t, path)
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		sw.buf.WriteString(strconv.Quote(rv.String()))
+	case reflect.Bool:
+		sw.buf.WriteString(strconv.FormatBool(rv.Bool()))
+	case reflect.Int:
+		sw.buf.WriteString(strconv.FormatInt(rv.Int(), 10))
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fmt.Fprintf(&sw.buf, "%s(%d)", t, rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		fmt.Fprintf(&sw.buf, "%s(%d)", t, rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		if math.IsNaN(rv.Float()) || math.IsInf(rv.Float(), 0) {
+			return fmt.Errorf("cannot generate source for %v at '%s', add a Rule for it", v, path)
+		}
+		fmt.Fprintf(&sw.buf, "%s(%s)", t, strconv.FormatFloat(rv.Float(), 'g', -1, t.Bits()))
+	case reflect.Map, reflect.Slice, reflect.Array:
+		if rv.Len() != 0 {
+			return fmt.Errorf("cannot generate source for non-empty %s at '%s'", t, path)
+		}
+		if containsNamedType(t) {
+			return fmt.Errorf("cannot generate source for value of type %s at '%s', add a Rule for it", t, path)
+		}
+		fmt.Fprintf(&sw.buf, "%s{}", t)
+	default:
+		return fmt.Errorf("cannot generate source for value of type %s at '%s', add a Rule for it", t, path)
+	}
+	return nil
+}
+
+func containsNamedType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Map:
+		return containsNamedType(t.Key()) || containsNamedType(t.Elem())
+	case reflect.Slice, reflect.Array, reflect.Ptr:
+		return containsNamedType(t.Elem())
+	}
+	return t.PkgPath() != ""
+}
@@ -0,0 +1,153 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package snapshot
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-lookslike"
+	"github.com/elastic/go-lookslike/isdef"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleDoc() map[string]interface{} {
+	return map[string]interface{}{
+		"@timestamp": time.Now(),
+		"monitor": map[string]interface{}{
+			"id":       "123e4567-e89b-12d3-a456-426614174000",
+			"duration": time.Second,
+			"status":   "up",
+			"check":    map[string]interface{}{"count": 3, "ratio": 0.5, "port": uint16(80)},
+		},
+		"tags":    []string{"a", "b"},
+		"empty":   []string{},
+		"nothing": nil,
+		"session": "abc",
+	}
+}
+
+func TestSchemaRoundTrip(t *testing.T) {
+	doc := sampleDoc()
+	g := NewGenerator(PathRule(`^session$`, isdef.IsNonEmptyString, "isdef.IsNonEmptyString"))
+	v := lookslike.Strict(lookslike.MustCompile(g.Schema(doc)))
+
+	res := v(doc)
+	require.True(t, res.Valid, "%v", res.Errors())
+
+	// Volatile fields accept other values of the same type
+	doc["@timestamp"] = time.Now().Add(time.Hour)
+	doc["session"] = "def"
+	doc["monitor"].(map[string]interface{})["id"] = "00000000-0000-0000-0000-000000000000"
+	assert.True(t, v(doc).Valid)
+
+	// Other fields are exact
+	doc["monitor"].(map[string]interface{})["status"] = "down"
+	assert.False(t, v(doc).Valid)
+}
+
+func TestGoSource(t *testing.T) {
+	g := NewGenerator(PathRule(`^session$`, isdef.IsNonEmptyString, "isdef.IsNonEmptyString"))
+	src, err := g.GoSource("mytest", "expectedEvent", sampleDoc())
+	require.NoError(t, err)
+
+	expected := `// Code generated by lookslike snapshot. DO NOT EDIT.
+
+package mytest
+
+import (
+	"github.com/elastic/go-lookslike"
+	"github.com/elastic/go-lookslike/isdef"
+	"time"
+)
+
+var expectedEvent = lookslike.MustCompile(map[string]interface{}{
+	"@timestamp": isdef.IsType[time.Time](),
+	"empty":      []string{},
+	"monitor": map[string]interface{}{
+		"check": map[string]interface{}{
+			"count": 3,
+			"port":  uint16(80),
+			"ratio": float64(0.5),
+		},
+		"duration": isdef.IsDuration,
+		"id":       isdef.IsUUID,
+		"status":   "up",
+	},
+	"nothing": nil,
+	"session": isdef.IsNonEmptyString,
+	"tags": []interface{}{
+		"a",
+		"b",
+	},
+})
+`
+	assert.Equal(t, expected, string(src))
+}
+
+func TestGoSourceUnsupportedValues(t *testing.T) {
+	type custom struct{}
+
+	_, _, err := NewGenerator().GoExpr(map[string]interface{}{"a": custom{}})
+	assert.Error(t, err)
+
+	_, _, err = NewGenerator().GoExpr(map[string]interface{}{"a": math.NaN()})
+	assert.Error(t, err)
+
+	// A rule makes unsupported values usable
+	g := NewGenerator(TypeRule(custom{}, isdef.KeyPresent, "isdef.KeyPresent"))
+	expr, imports, err := g.GoExpr(map[string]interface{}{"a": custom{}})
+	require.NoError(t, err)
+	assert.Contains(t, expr, `"a": isdef.KeyPresent`)
+	assert.Equal(t, []string{"github.com/elastic/go-lookslike/isdef"}, imports)
+}
+
+func TestGolden(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "schema_test.go")
+	g := NewGenerator()
+
+	written, err := g.Golden(filename, "mytest", "schema", map[string]interface{}{"a": 1})
+	require.NoError(t, err)
+	assert.True(t, written)
+
+	// Existing files are only rewritten when updating is enabled
+	written, err = g.Golden(filename, "mytest", "schema", map[string]interface{}{"a": 2})
+	require.NoError(t, err)
+	assert.False(t, written)
+	src, _ := os.ReadFile(filename)
+	assert.Contains(t, string(src), `"a": 1`)
+
+	g.Update = true
+	written, err = g.Golden(filename, "mytest", "schema", map[string]interface{}{"a": 2})
+	require.NoError(t, err)
+	assert.True(t, written)
+	src, _ = os.ReadFile(filename)
+	assert.Contains(t, string(src), `"a": 2`)
+
+	t.Setenv(UpdateEnv, "1")
+	written, err = NewGenerator().Golden(filename, "mytest", "schema", map[string]interface{}{"a": 3})
+	require.NoError(t, err)
+	assert.True(t, written)
+	src, _ = os.ReadFile(filename)
+	assert.Contains(t, string(src), `"a": 3`)
+}
@@ -18,30 +18,157 @@
 package testslike
 
 import (
+	"encoding/json"
+	"fmt"
+	"sort"
 	"testing"
 
+	"github.com/elastic/go-lookslike/llpath"
 	"github.com/elastic/go-lookslike/llresult"
 	"github.com/elastic/go-lookslike/validator"
-	"github.com/kortschak/utter"
-
-	"github.com/stretchr/testify/assert"
 )
 
+// Reporter reports failed validations to a test. Implement it to report through an assertion library such as
+// testify or gomega.
+type Reporter interface {
+	// Summary is called once when the value is invalid, with the results and the value that was validated.
+	// Sensitive values are already masked in both.
+	Summary(t testing.TB, results *llresult.Results, value interface{})
+	// Error is called once per failed check. When Subtests is used t is the subtest for the error's path.
+	Error(t testing.TB, err error)
+}
+
+// TBReporter is the default Reporter, it reports failures with testing.TB.Errorf.
+type TBReporter struct{}
+
+// Summary reports the number of errors along with the value, printed as indented JSON where possible.
+func (TBReporter) Summary(t testing.TB, results *llresult.Results, value interface{}) {
+	t.Helper()
+	t.Errorf("lookslike could not validate value, %d errors validating source:\n%s", len(results.Errors()), dump(value))
+}
+
+// Error reports the error.
+func (TBReporter) Error(t testing.TB, err error) {
+	t.Helper()
+	t.Errorf("%s", err)
+}
+
+func dump(value interface{}) string {
+	if out, err := json.MarshalIndent(value, "", "  "); err == nil {
+		return string(out)
+	}
+	return fmt.Sprintf("%#v", value)
+}
+
+// Option configures Test and Require.
+type Option func(*config)
+
+type config struct {
+	redaction *llresult.Redaction
+	subtests  bool
+	reporter  Reporter
+}
+
+// Redact masks the values at paths matching the given patterns in the failure output and the returned
+// results, see llresult.NewRedaction for the pattern syntax. Values checked with isdef.Sensitive are always
+// masked. Redact panics if a pattern is invalid.
+func Redact(patterns ...string) Option {
+	return func(c *config) {
+		c.redaction = llresult.MustNewRedaction(patterns...)
+	}
+}
+
+// Subtests reports the errors for each failing path in a subtest named after the path, so they can be
+// selected with -run. Subtests are only used when Test is given a *testing.T, other testing.TBs, including
+// *testing.B, get the errors reported directly, since running a sub-benchmark would time it.
+func Subtests() Option {
+	return func(c *config) {
+		c.subtests = true
+	}
+}
+
+// WithReporter reports failures with the given Reporter instead of TBReporter.
+func WithReporter(r Reporter) Option {
+	return func(c *config) {
+		c.reporter = r
+	}
+}
+
 // Test takes the output from a validator.Validator invocation and runs test assertions on the result.
 // If you are using this library for testing you will probably want to run Test(t, Compile(map[string]interface{}{...}), actual) as a pattern.
-func Test(t *testing.T, validator validator.Validator, value interface{}) *llresult.Results {
-	r := validator(value)
+func Test(t testing.TB, validator validator.Validator, value interface{}, opts ...Option) *llresult.Results {
+	t.Helper()
+	return test(t, validator, value, opts)
+}
 
+// Require is like Test, but stops the test with t.FailNow once all failures have been reported.
+func Require(t testing.TB, validator validator.Validator, value interface{}, opts ...Option) *llresult.Results {
+	t.Helper()
+	r := test(t, validator, value, opts)
 	if !r.Valid {
-		assert.Fail(
-			t,
-			"lookslike could not validate map",
-			"%d errors validating source: \n%s", len(r.Errors()), utter.Sdump(value),
-		)
+		t.FailNow()
 	}
+	return r
+}
+
+func test(t testing.TB, validator validator.Validator, value interface{}, opts []Option) *llresult.Results {
+	t.Helper()
+
+	c := config{reporter: TBReporter{}}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	r := c.redaction.Results(validator(value), value)
+	if r.Valid {
+		return r
+	}
+
+	c.reporter.Summary(t, r, c.redaction.Value(r, value))
 
-	for _, err := range r.Errors() {
-		assert.NoError(t, err)
+	if !c.subtests {
+		for _, err := range r.Errors() {
+			c.reporter.Error(t, err)
+		}
+		return r
+	}
+
+	errs := r.DetailedErrors()
+	paths := make([]string, 0, len(errs.Fields))
+	for p := range errs.Fields {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		pathErrs := llresult.NewResults()
+		for _, vr := range errs.Fields[p] {
+			pathErrs.Record(llpath.MustParsePath(p), vr)
+		}
+		run(t, subtestName(p), func(t testing.TB) {
+			t.Helper()
+			for _, err := range pathErrs.Errors() {
+				c.reporter.Error(t, err)
+			}
+		})
 	}
 	return r
 }
+
+// subtestName names the subtest for a path, the root path has an empty name which testing would replace.
+func subtestName(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
+
+// run runs fn as a subtest if t is a *testing.T, and directly otherwise.
+func run(t testing.TB, name string, fn func(t testing.TB)) {
+	t.Helper()
+	if tt, ok := t.(*testing.T); ok {
+		tt.Run(name, func(t *testing.T) { fn(t) })
+		return
+	}
+	fn(t)
+}
@@ -1,9 +1,15 @@
 package testslike
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/elastic/go-lookslike"
+	"github.com/elastic/go-lookslike/isdef"
+	"github.com/elastic/go-lookslike/llresult"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTest(t *testing.T) {
@@ -17,3 +23,103 @@ func TestTest(t *testing.T) {
 	}
 	Test(t, validator, val)
 }
+
+// fakeTB records failures instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	errors  []string
+	helpers int
+	failNow bool
+}
+
+func (f *fakeTB) FailNow()     { f.failNow = true }
+func (f *fakeTB) Helper()      { f.helpers++ }
+func (f *fakeTB) Name() string { return "fake" }
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestTestFailures(t *testing.T) {
+	validator := lookslike.MustCompile(map[string]interface{}{
+		"foo":   "bar",
+		"token": isdef.Sensitive(isdef.IsStringWithPrefix("tok_")),
+	})
+	val := map[string]interface{}{
+		"foo":   "baz",
+		"token": "secret-value",
+	}
+
+	for _, opts := range [][]Option{nil, {Subtests()}} {
+		tb := &fakeTB{}
+		res := Test(tb, validator, val, opts...)
+		assert.False(t, res.Valid)
+		assert.NotZero(t, tb.helpers)
+
+		// One summary with a dump of the value, then one failure per error
+		require.Len(t, tb.errors, 3)
+		assert.Contains(t, tb.errors[0], "2 errors validating source")
+		assert.Contains(t, tb.errors[0], `"foo": "baz"`)
+		for _, e := range tb.errors {
+			assert.NotContains(t, e, "secret-value")
+		}
+	}
+}
+
+func TestSubtestName(t *testing.T) {
+	assert.Equal(t, "(root)", subtestName(""))
+	assert.Equal(t, "a.[0].b", subtestName("a.[0].b"))
+}
+
+func TestRequire(t *testing.T) {
+	validator := lookslike.MustCompile(map[string]interface{}{"foo": "bar"})
+
+	tb := &fakeTB{}
+	Require(tb, validator, map[string]interface{}{"foo": "bar"})
+	assert.False(t, tb.failNow)
+	assert.Empty(t, tb.errors)
+
+	Require(tb, validator, map[string]interface{}{"foo": "baz"})
+	assert.True(t, tb.failNow)
+	assert.Len(t, tb.errors, 2)
+}
+
+type recordingReporter struct {
+	summaries int
+	errs      []error
+	// names holds the name of the testing.TB each error was reported to
+	names []string
+}
+
+func (r *recordingReporter) Summary(_ testing.TB, _ *llresult.Results, _ interface{}) { r.summaries++ }
+func (r *recordingReporter) Error(t testing.TB, err error) {
+	r.errs = append(r.errs, err)
+	r.names = append(r.names, t.Name())
+}
+
+func TestWithReporter(t *testing.T) {
+	validator := lookslike.MustCompile(map[string]interface{}{"foo": "bar", "a": 1})
+
+	tb := &fakeTB{}
+	rep := &recordingReporter{}
+	Test(tb, validator, map[string]interface{}{"foo": "baz", "a": 1}, WithReporter(rep))
+
+	assert.Empty(t, tb.errors)
+	assert.Equal(t, 1, rep.summaries)
+	require.Len(t, rep.errs, 1)
+	assert.Contains(t, rep.errs[0].Error(), "@Path 'foo'")
+}
+
+func TestSubtestsWithTestingT(t *testing.T) {
+	validator := lookslike.MustCompile(map[string]interface{}{"foo": "bar", "a": 1})
+
+	rep := &recordingReporter{}
+	var parent string
+	t.Run("wrapper", func(t *testing.T) {
+		parent = t.Name()
+		Test(t, validator, map[string]interface{}{"foo": "baz", "a": 2}, Subtests(), WithReporter(rep))
+	})
+
+	assert.Equal(t, 1, rep.summaries)
+	require.Len(t, rep.errs, 2)
+	assert.Equal(t, []string{parent + "/a", parent + "/foo"}, rep.names)
+}